@@ -12,9 +12,39 @@ type Alquran interface {
 	Ayat(id int) Ayat
 }
 
+// Navigator is implemented by Alquran able to fetch verses by ID and by
+// reference, returning error instead of panic for invalid ones.
+type Navigator interface {
+	Lookup(id ID) (Ayat, error)
+	Verses(ref Reference) ([]Ayat, error)
+}
+
+// Translator is implemented by Alquran holding translations in more than
+// one language, Ayat.Translation is translation in the default language.
+type Translator interface {
+	// Languages returns language of every translation, the default first.
+	Languages() []string
+	// Translation returns translation of verse id in lang.
+	Translation(id ID, lang string) (string, error)
+}
+
 // Info ...
 type Info struct {
 	ChapterNo   int
 	ChapterName string
 	VerseNo     int
+	Juz, Hizb   int
+	// Quarter is rub' al-hizb number from 1 to 240, zero if unknown.
+	Quarter int
+	Manzil  int
+	// Page is page number in Madani mushaf, zero if unknown.
+	Page int
+	// Ruku is ruku' number from the start of the Quran, zero if unknown.
+	Ruku   int
+	Sajdah bool
+	// Revelation is revelation place of the chapter, "Meccan" or
+	// "Medinan", empty if unknown.
+	Revelation string
+	// RevelationOrder is revelation order of the chapter, zero if unknown.
+	RevelationOrder int
 }
@@ -41,6 +41,7 @@ func main() {
 
 	sc := bufio.NewScanner(docs)
 	fWriter := bufio.NewWriter(f)
+	encoder := arabic.NewEncoder(arabic.LettersUthmani)
 
 	// profiling
 	timeStart := time.Now()
@@ -51,11 +52,7 @@ func main() {
 		// [2] = ayat number
 		// [3] = ayat text
 		data := bytes.Split(sc.Bytes(), []byte("|"))
-		var encoder arabic.Encoder
-		encoder.SetLettersMode(arabic.LettersUthmani)
-		encoder.SetHarakat(*vowel)
-		phonetic := encoder.Encode(data[3])
-		encoder.Encode(data[3])
+		phonetic := encoder.Encode(data[3], *vowel)
 
 		fmt.Printf("%d. Processing surat {%s} ayat {%s}\n", id, data[0], data[2])
 		fmt.Fprintf(fWriter, "%d|%s\n", id, string(phonetic[:]))
@@ -4,49 +4,37 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
-	"github.com/billyzaelani/go-lafzi/file"
+	"github.com/billyzaelani/go-lafzi/config"
 	"github.com/billyzaelani/go-lafzi/http"
-	"github.com/billyzaelani/go-lafzi/pkg/phonetic/latin"
-	"github.com/billyzaelani/go-lafzi/search"
+	"github.com/billyzaelani/go-lafzi/web"
+	"github.com/billyzaelani/go-lafzi/web/template"
 )
 
 func main() {
-	var (
-		termlistV = "data/index/termlist_vowel.txt"
-		termlistN = "data/index/termlist.txt"
-		postlistV = "data/index/postlist_vowel.txt"
-		postlistN = "data/index/postlist.txt"
-
-		listenAddr = flag.String("listen", ":8080", "HTTP listen address, default :8080")
-
-		alquranFilename         = "data/quran/uthmani.txt"
-		translationFilename     = "data/translation/trans-indonesian.txt"
-		transliterationFilename = flag.String("transliteration", "default.txt", "transliteration filename located in /data/transliteration/")
-	)
-	flag.Parse()
-
-	index, err := file.NewIndex(
-		termlistV, termlistN,
-		postlistV, postlistN,
-	)
+	c, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer index.Close()
 
-	alquran, err := file.NewAlquran(alquranFilename, translationFilename)
+	lafzi, err := c.Open()
 	if err != nil {
 		log.Fatal(err)
 	}
-	m, err := alquran.GenerateMap(*transliterationFilename)
-	if err != nil {
+	defer lafzi.Close()
+
+	webFS := web.FS(c.Web)
+	if err := template.Load(webFS); err != nil {
 		log.Fatal(err)
 	}
 
-	s := search.NewService(latin.NewEncoder(m), index, alquran)
-
-	server := http.NewServer(*listenAddr, http.Search(s))
-	fmt.Printf("Listening on %s\n", *listenAddr)
+	server := http.NewServer(c.HTTP.Listen,
+		http.Search(lafzi, c.SearchOptions(), lafzi.Alquran.Languages()...),
+		http.Asset(webFS))
+	server.ReadTimeout = time.Duration(c.HTTP.ReadTimeout)
+	server.WriteTimeout = time.Duration(c.HTTP.WriteTimeout)
+	fmt.Printf("Listening on %s\n", c.HTTP.Listen)
 	log.Fatal(server.ListenAndServe())
 }
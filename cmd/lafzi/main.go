@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/billyzaelani/go-lafzi/file"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/arabic"
+)
+
+const usage = `Usage: lafzi <command> [arguments]
+
+The commands are:
+
+	build	build phonetic corpus and index from alquran text
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "build":
+		build(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "lafzi: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func build(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	var (
+		alquranFilename = fs.String("quran", "data/quran/uthmani.txt", "alquran `file`: .xml Tanzil XML, .json array of verses, otherwise lines of chapter|name|verse|text or chapter|verse|text")
+		outDir          = fs.String("out", "data/index", "output directory of phonetic corpus, index and manifest")
+		script          = fs.String("script", "uthmani", "script of alquran text: uthmani or simple")
+	)
+	fs.Parse(args)
+
+	mode, err := arabic.ParseLettersMode(*script)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	timeStart := time.Now()
+
+	m, err := file.Build(*alquranFilename, *outDir, mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	timeEnd := time.Now()
+	timeElapsed := timeEnd.Sub(timeStart)
+
+	fmt.Printf("Processed %d verses in %f second\n", m.Documents, timeElapsed.Seconds())
+	fmt.Printf("Save files in %s:\n", *outDir)
+	for _, name := range []string{m.Vowel.Phonetic, m.NonVowel.Phonetic, m.Vowel.Index, m.NonVowel.Index, file.ManifestName} {
+		fmt.Printf("-%s\n", name)
+	}
+}
@@ -4,59 +4,93 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/billyzaelani/go-lafzi/file"
-	"github.com/billyzaelani/go-lafzi/pkg/phonetic/latin"
+	"github.com/billyzaelani/go-lafzi/config"
+	"github.com/billyzaelani/go-lafzi/pkg/sequence"
 	"github.com/billyzaelani/go-lafzi/search"
 )
 
 func main() {
 	var (
-		termlistV = "data/index/termlist_vowel.txt"
-		termlistN = "data/index/termlist.txt"
-		postlistV = "data/index/postlist_vowel.txt"
-		postlistN = "data/index/postlist.txt"
-
-		alquranFilename         = "data/quran/uthmani.txt"
-		translationFilename     = "data/translation/trans-indonesian.txt"
-		transliterationFilename = flag.String("transliteration", "default.txt", "transliteration filename located in /data/transliteration/")
-
-		q = flag.String("q", "", "query")
-		v = flag.Bool("v", true, "phonetic encoding involving using vowel or not")
+		q      = flag.String("q", "", "query")
+		v      = flag.Bool("v", true, "phonetic encoding involving using vowel or not")
+		th     = flag.Float64("th", -1, "filter threshold, negative for threshold of config")
+		filter = flag.Bool("filter", true, "filter documents under threshold")
+		idf    = flag.Bool("idf", false, "weight trigrams by inverse document frequency")
+		maxGap = flag.Int("maxgap", 0, "maximum gap of subsequence positions, 0 for default")
+		minRun = flag.Int("minrun", 0, "minimum length of subsequence")
+		rerank = flag.Int("rerank", 0, "number of best documents reranked by local alignment")
+		verses = flag.Int("verses", 1, "maximum number of adjacent verses searched as continuous text")
+		surah  = flag.String("surah", "", "chapters to search such as 2, 1-3 or 2:1-5, comma separated")
+		juz    = flag.String("juz", "", "juz to search such as 30 or 29-30, comma separated")
+		hizb   = flag.String("hizb", "", "hizb to search such as 60 or 59-60, comma separated")
+		lang   = flag.String("lang", "", "language of translation, empty for default")
+		order  = flag.Bool("order", true, "order by score, otherwise by matched tokens count")
+		scorer = flag.String("scorer", "", "scorer overriding order: subsequence, count, tfidf or bm25")
+		limit  = flag.Int("limit", 0, "maximum number of documents, 0 for all")
+		offset = flag.Int("offset", 0, "number of documents to skip")
 	)
-	flag.Parse()
+	c, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *th < 0 {
+		*th = c.Threshold
+	}
 
 	timeStart := time.Now()
 
-	index, err := file.NewIndex(
-		termlistV, termlistN,
-		postlistV, postlistN,
-	)
+	s, err := c.Open()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer index.Close()
+	defer s.Close()
 
-	alquran, err := file.NewAlquran(alquranFilename, translationFilename)
-	if err != nil {
-		log.Fatal(err)
+	opts := search.Options{
+		Vowel:           *v,
+		ScoreOrder:      *order,
+		Filter:          *filter,
+		FilterThreshold: *th,
+		IDF:             *idf,
+		Sequence:        sequence.Config{MaxGap: *maxGap, MinRunLength: *minRun},
+		Limit:           *limit,
+		Offset:          *offset,
+		Rerank:          *rerank,
+		Verses:          *verses,
+		Language:        *lang,
+	}
+	for d, scopes := range map[search.Division]string{search.Chapter: *surah, search.Juz: *juz, search.Hizb: *hizb} {
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope == "" {
+				continue
+			}
+			sc, err := search.ParseScope(d, scope)
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts.Scope = append(opts.Scope, sc)
+		}
 	}
-	m, err := alquran.GenerateMap(*transliterationFilename)
+	if *scorer != "" {
+		if opts.Scorer, err = search.NewScorer(*scorer); err != nil {
+			log.Fatal(err)
+		}
+	}
+	res, err := s.Search([]byte(*q), opts)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	s := search.NewService(latin.NewEncoder(m), index, alquran)
-
-	res := s.Search([]byte(*q), *v)
 	docs := res.Docs
 	fmt.Printf("Query\t\t\t: %s\n", res.Query)
 	fmt.Printf("Phonetic code\t\t: %s\n", res.PhoneticCode)
 	fmt.Printf("Trigram count\t\t: %d\n", res.TrigramCount)
 	fmt.Printf("Document found\t\t: %d\n", res.FoundDoc)
 	fmt.Printf("Filter threshold\t: %.2f\n", res.FilterThreshold)
-	fmt.Printf("Score minimum\t\t: %.2f\n\n", res.MinScore)
+	fmt.Printf("Score minimum\t\t: %.2f\n", res.MinScore)
+	fmt.Printf("Score maximum\t\t: %.2f\n\n", res.MaxScore)
 
 	n := len(docs)
 	if n > 10 {
@@ -64,10 +98,21 @@ func main() {
 	}
 	// only top 10
 	for i, doc := range docs[:n] {
-		fmt.Printf("%d.\tID: %d\n", i+1, doc.ID)
+		fmt.Printf("%d.\tID: %d (%s)\n", i+1, doc.ID, doc.Reference())
 		fmt.Printf("\tScore: %.2f\n", doc.Score)
+		fmt.Printf("\tJuz: %d, hizb: %d, manzil: %d, page: %d, sajdah: %v\n", doc.Juz, doc.Hizb, doc.Manzil, doc.Page, doc.Sajdah)
+		if *lang != "" {
+			fmt.Printf("\tTranslation: %s\n", doc.Translation)
+		}
 		fmt.Printf("\tSequence: %v\n", &doc.Sequence)
-		fmt.Printf("\tSubsequence: %v\n\n", doc.Subsequence)
+		fmt.Printf("\tSubsequence: %v\n", doc.Subsequence)
+		if *rerank > 0 {
+			fmt.Printf("\tAlignment: %+v\n", doc.Alignment)
+		}
+		for _, hl := range doc.Highlight {
+			fmt.Printf("\tHighlight: %s\n", doc.Arabic[hl.Start:hl.End])
+		}
+		fmt.Println()
 	}
 
 	timeEnd := time.Now()
@@ -0,0 +1,313 @@
+// Package config loads configuration of lafzi commands from JSON file,
+// LAFZI_* environment variables and flags. A configuration file such as
+//
+//	{
+//		"data": "/srv/lafzi/data",
+//		"translations": {"en": "translation/en.sahih.xml"},
+//		"threshold": 0.6,
+//		"http": {"listen": ":8081", "readTimeout": "10s"}
+//	}
+//
+// sets fields present in it, the same as LAFZI_DATA=/srv/lafzi/data or
+// flag -data /srv/lafzi/data.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/billyzaelani/go-lafzi/data"
+	"github.com/billyzaelani/go-lafzi/search"
+)
+
+// Config is configuration of lafzi commands. File names are paths in Data
+// directory, which overrides data embedded by package data.
+type Config struct {
+	// Data is directory overriding embedded data files, empty for
+	// embedded only.
+	Data string `json:"data"`
+	// Web is directory overriding embedded templates and assets, empty
+	// for embedded only.
+	Web string `json:"web"`
+	// Corpus is the Quran in format of file.FormatOf.
+	Corpus string `json:"corpus"`
+	// Metadata is optional Tanzil metadata of Corpus.
+	Metadata string `json:"metadata"`
+	// Translation is translation in file.DefaultLanguage.
+	Translation string `json:"translation"`
+	// Translations are additional translations by language.
+	Translations map[string]string `json:"translations,omitempty"`
+	// Index is index directory generated by file.Build.
+	Index string `json:"index"`
+	// Memory loads Index into memory by memory.Load, instead of reading
+	// postings from index files on every search.
+	Memory bool `json:"memory"`
+	// Transliteration is name of letters map in map directory, generated
+	// from transliteration directory of Data if missing.
+	Transliteration string `json:"transliteration"`
+	// Encoder encodes latin queries, "latin" using letters map of
+	// Transliteration or "indonesia".
+	Encoder string `json:"encoder"`
+	// Threshold is default filter threshold of search.
+	Threshold float64 `json:"threshold"`
+	HTTP      HTTP    `json:"http"`
+}
+
+// HTTP is configuration of HTTP server.
+type HTTP struct {
+	Listen       string   `json:"listen"`
+	ReadTimeout  Duration `json:"readTimeout"`
+	WriteTimeout Duration `json:"writeTimeout"`
+}
+
+// Duration is time.Duration written as string such as "15s" in JSON.
+type Duration time.Duration
+
+// MarshalJSON ...
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON ...
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+// Set implements flag.Value.
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) String() string {
+	return time.Duration(*d).String()
+}
+
+// Default returns configuration using embedded data and data and web
+// directories of the working directory.
+func Default() Config {
+	return Config{
+		Data:            "data",
+		Web:             "web",
+		Corpus:          data.AlquranName,
+		Metadata:        data.MetadataName,
+		Translation:     data.TranslationName,
+		Index:           data.IndexDir,
+		Transliteration: "default.txt",
+		Encoder:         "latin",
+		Threshold:       search.DefaultFilterThreshold,
+		HTTP: HTTP{
+			Listen:       ":8080",
+			ReadTimeout:  Duration(15 * time.Second),
+			WriteTimeout: Duration(15 * time.Second),
+		},
+	}
+}
+
+// setting is a configuration field set by flag name and environment
+// variable LAFZI_NAME, where dash of name is underscore.
+type setting struct {
+	name, usage string
+	value       func(c *Config) flag.Value
+}
+
+var settings = []setting{
+	{"data", "`directory` overriding embedded data files, empty for embedded only",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Data) }},
+	{"web", "`directory` overriding embedded templates and assets, empty for embedded only",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Web) }},
+	{"corpus", "Quran `file` in data directory, .txt, .xml or .json",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Corpus) }},
+	{"metadata", "optional Tanzil metadata `file` in data directory",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Metadata) }},
+	{"translation", "translation `file` in data directory of the default language",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Translation) }},
+	{"translations", "additional translations in data directory as lang=filename, comma separated",
+		func(c *Config) flag.Value { return (*translationsValue)(&c.Translations) }},
+	{"index", "index `directory` in data directory",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Index) }},
+	{"memory", "load index into memory instead of reading it on every search",
+		func(c *Config) flag.Value { return (*boolValue)(&c.Memory) }},
+	{"transliteration", "letters map `filename` located in map or transliteration of data directory",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Transliteration) }},
+	{"encoder", "`encoder` of latin query: latin or indonesia",
+		func(c *Config) flag.Value { return (*stringValue)(&c.Encoder) }},
+	{"threshold", "default filter `threshold` in range [0, 1]",
+		func(c *Config) flag.Value { return (*floatValue)(&c.Threshold) }},
+	{"listen", "HTTP listen `address`",
+		func(c *Config) flag.Value { return (*stringValue)(&c.HTTP.Listen) }},
+	{"read-timeout", "HTTP read `timeout` such as 15s",
+		func(c *Config) flag.Value { return &c.HTTP.ReadTimeout }},
+	{"write-timeout", "HTTP write `timeout` such as 15s",
+		func(c *Config) flag.Value { return &c.HTTP.WriteTimeout }},
+}
+
+// EnvName returns environment variable of flag name.
+func EnvName(name string) string {
+	return "LAFZI_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// Load defines flags of every setting and -config in fs, parses args and
+// returns configuration of Default overridden by JSON file of -config or
+// LAFZI_CONFIG, then by LAFZI_* environment variables, then by flags set
+// in args.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	c := Default()
+	name := fs.String("config", os.Getenv(EnvName("config")), "JSON configuration file")
+	// flags are parsed into defaults, only those set override c
+	defaults := Default()
+	for _, s := range settings {
+		fs.Var(s.value(&defaults), s.name, s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	if *name != "" {
+		if err := c.ReadFile(*name); err != nil {
+			return c, err
+		}
+	}
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(EnvName(s.name)); ok {
+			if err := s.value(&c).Set(v); err != nil {
+				return c, fmt.Errorf("config: invalid %s %q: %v", EnvName(s.name), v, err)
+			}
+		}
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.name == f.Name && err == nil {
+				err = s.value(&c).Set(f.Value.String())
+			}
+		}
+	})
+	if err != nil {
+		return c, fmt.Errorf("config: %v", err)
+	}
+	return c, c.Validate()
+}
+
+// ReadFile sets fields of c present in JSON file name, unknown fields are
+// error.
+func (c *Config) ReadFile(name string) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config %s: %v", name, err)
+	}
+	return nil
+}
+
+// Validate returns error if a field of c is out of its range.
+func (c Config) Validate() error {
+	switch {
+	case c.Encoder != "latin" && c.Encoder != "indonesia":
+		return fmt.Errorf("config: invalid encoder %q", c.Encoder)
+	case c.Threshold < 0 || c.Threshold > 1:
+		return fmt.Errorf("config: invalid threshold %v", c.Threshold)
+	case c.HTTP.ReadTimeout < 0 || c.HTTP.WriteTimeout < 0:
+		return fmt.Errorf("config: negative HTTP timeout")
+	}
+	return nil
+}
+
+type stringValue string
+
+func (s *stringValue) Set(v string) error {
+	*s = stringValue(v)
+	return nil
+}
+
+func (s *stringValue) String() string {
+	return string(*s)
+}
+
+type boolValue bool
+
+func (b *boolValue) Set(v string) error {
+	x, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(x)
+	return nil
+}
+
+func (b *boolValue) String() string {
+	return strconv.FormatBool(bool(*b))
+}
+
+// IsBoolFlag allows -memory without value.
+func (b *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+type floatValue float64
+
+func (f *floatValue) Set(v string) error {
+	x, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	*f = floatValue(x)
+	return nil
+}
+
+func (f *floatValue) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 64)
+}
+
+// translationsValue adds translations written as lang=filename, comma
+// separated.
+type translationsValue map[string]string
+
+func (t *translationsValue) Set(v string) error {
+	for _, tr := range strings.Split(v, ",") {
+		if tr = strings.TrimSpace(tr); tr == "" {
+			continue
+		}
+		i := strings.IndexByte(tr, '=')
+		if i <= 0 {
+			return fmt.Errorf("invalid translation %q, expected lang=filename", tr)
+		}
+		if *t == nil {
+			*t = make(translationsValue)
+		}
+		(*t)[tr[:i]] = tr[i+1:]
+	}
+	return nil
+}
+
+func (t *translationsValue) String() string {
+	if t == nil {
+		return ""
+	}
+	var trs []string
+	for lang, name := range *t {
+		trs = append(trs, lang+"="+name)
+	}
+	sort.Strings(trs)
+	return strings.Join(trs, ",")
+}
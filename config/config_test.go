@@ -0,0 +1,153 @@
+package config_test
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/billyzaelani/go-lafzi/config"
+	"github.com/billyzaelani/go-lafzi/data"
+	"github.com/billyzaelani/go-lafzi/memory"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "lafzi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "lafzi.json")
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestLoad(t *testing.T) {
+	name := writeConfig(t, `{
+		"data": "/srv/lafzi",
+		"encoder": "indonesia",
+		"threshold": 0.7,
+		"translations": {"en": "translation/en.xml"},
+		"http": {"listen": ":9090", "readTimeout": "5s"}
+	}`)
+	defer os.RemoveAll(filepath.Dir(name))
+	os.Setenv("LAFZI_THRESHOLD", "0.8")
+	os.Setenv("LAFZI_READ_TIMEOUT", "10s")
+	os.Setenv("LAFZI_MEMORY", "true")
+	defer os.Unsetenv("LAFZI_THRESHOLD")
+	defer os.Unsetenv("LAFZI_READ_TIMEOUT")
+	defer os.Unsetenv("LAFZI_MEMORY")
+
+	fs := flag.NewFlagSet("lafzi", flag.ContinueOnError)
+	q := fs.String("q", "", "query")
+	c, err := config.Load(fs, []string{"-config", name, "-threshold", "0.9", "-translations", "ms=ms.txt", "-q", "kun"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config.Default()
+	expected.Data = "/srv/lafzi"
+	expected.Encoder = "indonesia"
+	expected.Threshold = 0.9
+	expected.Memory = true
+	expected.Translations = map[string]string{"en": "translation/en.xml", "ms": "ms.txt"}
+	expected.HTTP.Listen = ":9090"
+	expected.HTTP.ReadTimeout = config.Duration(10 * time.Second)
+	if !reflect.DeepEqual(expected, c) {
+		t.Errorf("expected: %+v, actual: %+v", expected, c)
+	}
+	if *q != "kun" {
+		t.Errorf("expected: kun, actual: %s", *q)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	unknown := writeConfig(t, `{"listen": ":9090"}`)
+	defer os.RemoveAll(filepath.Dir(unknown))
+
+	tables := [][]string{
+		{"-config", unknown},
+		{"-encoder", "arabic"},
+		{"-threshold", "2"},
+		{"-read-timeout", "-1s"},
+		{"-translations", "en"},
+		{"-memory=maybe"},
+	}
+	for _, args := range tables {
+		fs := flag.NewFlagSet("lafzi", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		if _, err := config.Load(fs, args); err == nil {
+			t.Errorf("args: %v error, expected: error, actual: %v", args, err)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	c := config.Default()
+	c.Data = ""
+	lafzi, err := c.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lafzi.Close()
+
+	res, err := lafzi.Search([]byte("alhamdulillahi rabbil alamin"), c.SearchOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Docs) == 0 || res.Docs[0].Reference() != "1:2" {
+		t.Errorf("expected: 1:2 first, actual: %d docs", len(res.Docs))
+	}
+
+	c.Memory = true
+	memoryLafzi, err := c.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer memoryLafzi.Close()
+	if _, ok := memoryLafzi.Index.(*memory.Index); !ok {
+		t.Errorf("expected: *memory.Index, actual: %T", memoryLafzi.Index)
+	}
+	memoryRes, err := memoryLafzi.Search([]byte("alhamdulillahi rabbil alamin"), c.SearchOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, memoryRes) {
+		t.Errorf("expected: %+v, actual: %+v", res, memoryRes)
+	}
+
+	c.Translations = map[string]string{"en": "translation/missing.txt"}
+	if _, err := c.Open(); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+
+	// verses are encoded in script of the manifest
+	b, err := fs.ReadFile(data.FS(""), path.Join(data.IndexDir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = bytes.Replace(b, []byte(`"uthmani"`), []byte(`"imlaei"`), 1)
+	dir, err := ioutil.TempDir("", "lafzi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, data.IndexDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, data.IndexDir, "manifest.json"), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c = config.Default()
+	c.Data = dir
+	if _, err := c.Open(); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
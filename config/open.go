@@ -0,0 +1,133 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/data"
+	"github.com/billyzaelani/go-lafzi/file"
+	"github.com/billyzaelani/go-lafzi/memory"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/arabic"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/indonesia"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/latin"
+	"github.com/billyzaelani/go-lafzi/search"
+)
+
+// Lafzi is search service of index and alquran opened by Open. Index is
+// *file.Index, or *memory.Index if Config.Memory is true.
+type Lafzi struct {
+	search.Service
+	Index   lafzi.Index
+	Alquran *file.Alquran
+}
+
+// Close closes the index.
+func (l *Lafzi) Close() {
+	closeIndex(l.Index)
+}
+
+// closeIndex closes index if it reads files.
+func closeIndex(index lafzi.Index) {
+	if c, ok := index.(interface{ Close() }); ok {
+		c.Close()
+	}
+}
+
+// Open opens index, alquran with metadata and translations, and encoder
+// of c from data directory of c. Verses are encoded in script of the
+// index manifest.
+func (c Config) Open() (*Lafzi, error) {
+	fsys := data.FS(c.Data)
+	m, err := file.ReadManifestFS(fsys, c.Index)
+	if err != nil {
+		return nil, err
+	}
+	script, err := arabic.ParseLettersMode(m.Script)
+	if err != nil {
+		return nil, err
+	}
+	index, err := c.openIndex(fsys, m)
+	if err != nil {
+		return nil, err
+	}
+	alquran, err := c.openAlquran(fsys)
+	if err != nil {
+		closeIndex(index)
+		return nil, err
+	}
+	encoder, err := c.encoder(fsys, alquran)
+	if err != nil {
+		closeIndex(index)
+		return nil, err
+	}
+
+	return &Lafzi{
+		Service: search.NewServiceScript(encoder, index, alquran, script),
+		Index:   index,
+		Alquran: alquran,
+	}, nil
+}
+
+func (c Config) openIndex(fsys fs.FS, m *file.Manifest) (lafzi.Index, error) {
+	if !c.Memory {
+		return file.NewIndexDirFS(fsys, c.Index)
+	}
+	fv, err := fsys.Open(path.Join(c.Index, m.Vowel.Index))
+	if err != nil {
+		return nil, err
+	}
+	defer fv.Close()
+	fn, err := fsys.Open(path.Join(c.Index, m.NonVowel.Index))
+	if err != nil {
+		return nil, err
+	}
+	defer fn.Close()
+	return memory.Load(fv, fn)
+}
+
+func (c Config) openAlquran(fsys fs.FS) (*file.Alquran, error) {
+	alquran, err := file.NewAlquranFS(fsys, c.Corpus, c.Translation)
+	if err != nil {
+		return nil, err
+	}
+	// metadata is optional, juz, hizb, manzil and sajdah are known without it
+	if c.Metadata != "" {
+		if err := alquran.LoadMetadataFS(fsys, c.Metadata); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	// add in order of language, so Languages does not depend on map order
+	langs := make([]string, 0, len(c.Translations))
+	for lang := range c.Translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if err := alquran.AddTranslationFS(fsys, lang, c.Translations[lang]); err != nil {
+			return nil, err
+		}
+	}
+	return alquran, nil
+}
+
+func (c Config) encoder(fsys fs.FS, alquran *file.Alquran) (phonetic.Encoder, error) {
+	if c.Encoder == "indonesia" {
+		return indonesia.Encoder{}, nil
+	}
+	m, err := alquran.GenerateMapFS(fsys, c.Transliteration)
+	if err != nil {
+		return nil, err
+	}
+	return latin.NewEncoder(m), nil
+}
+
+// SearchOptions returns search.DefaultOptions with filter threshold of c.
+func (c Config) SearchOptions() search.Options {
+	opts := search.DefaultOptions()
+	opts.FilterThreshold = c.Threshold
+	return opts
+}
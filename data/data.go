@@ -0,0 +1,34 @@
+// Package data embeds the default corpus, translation, letters map and
+// prebuilt index.
+package data
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/billyzaelani/go-lafzi/pkg/overlay"
+)
+
+// Names of embedded files.
+const (
+	AlquranName     = "quran/uthmani.txt"
+	MetadataName    = "quran/quran-data.xml"
+	TranslationName = "translation/trans-indonesian.txt"
+	MapDir          = "map"
+	IndexDir        = "index"
+)
+
+//go:embed quran/uthmani.txt translation/trans-indonesian.txt map/default.txt
+//go:embed index/manifest.json index/index.lfz index/index_vowel.lfz
+var embedded embed.FS
+
+// FS returns file system of the default data embedded in the binary,
+// overridden by files of directory dir if dir is not empty. MetadataName
+// is not embedded, it is only read from dir.
+func FS(dir string) fs.FS {
+	if dir == "" {
+		return embedded
+	}
+	return overlay.New(os.DirFS(dir), embedded)
+}
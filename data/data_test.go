@@ -0,0 +1,31 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/data"
+	"github.com/billyzaelani/go-lafzi/file"
+)
+
+func TestEmbedded(t *testing.T) {
+	fsys := data.FS("")
+	index, err := file.NewIndexDirFS(fsys, data.IndexDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+	alquran, err := file.NewAlquranFS(fsys, data.AlquranName, data.TranslationName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, actual := 6236, index.DocumentCount(true); actual != expected {
+		t.Errorf("expected: %d, actual: %d", expected, actual)
+	}
+	if ayat := alquran.Ayat(262); ayat.ChapterNo != 2 || ayat.VerseNo != 255 {
+		t.Errorf("expected: 2:255, actual: %d:%d", ayat.ChapterNo, ayat.VerseNo)
+	}
+	if _, err := alquran.GenerateMapFS(fsys, "default.txt"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,80 @@
+package lafzi
+
+import "sort"
+
+// verse is chapter and verse number where a division starts.
+type verse struct {
+	chapter, verse int
+}
+
+func (v verse) before(chapter, verseNo int) bool {
+	return v.chapter < chapter || v.chapter == chapter && v.verse <= verseNo
+}
+
+// hizbStart is the first verse of every hizb, every juz is two hizb.
+var hizbStart = [...]verse{
+	{1, 1}, {2, 75}, {2, 142}, {2, 203}, {2, 253}, {3, 15},
+	{3, 93}, {3, 171}, {4, 24}, {4, 88}, {4, 148}, {5, 27},
+	{5, 82}, {6, 36}, {6, 111}, {7, 1}, {7, 88}, {7, 171},
+	{8, 41}, {9, 34}, {9, 93}, {10, 26}, {11, 6}, {11, 84},
+	{12, 53}, {13, 19}, {15, 1}, {16, 51}, {17, 1}, {17, 99},
+	{18, 75}, {20, 1}, {21, 1}, {22, 1}, {23, 1}, {24, 21},
+	{25, 21}, {26, 111}, {27, 56}, {28, 51}, {29, 46}, {31, 22},
+	{33, 31}, {34, 24}, {36, 28}, {37, 145}, {39, 32}, {40, 41},
+	{41, 47}, {43, 24}, {46, 1}, {48, 18}, {51, 31}, {55, 1},
+	{58, 1}, {62, 1}, {67, 1}, {72, 1}, {78, 1}, {87, 1},
+}
+
+// manzilStart is the first verse of every manzil.
+var manzilStart = [...]verse{
+	{1, 1}, {5, 1}, {10, 1}, {17, 1}, {26, 1}, {37, 1}, {50, 1},
+}
+
+// sajdah are verses of prostration.
+var sajdah = [...]verse{
+	{7, 206}, {13, 15}, {16, 50}, {17, 109}, {19, 58},
+	{22, 18}, {22, 77}, {25, 60}, {27, 26}, {32, 15},
+	{38, 24}, {41, 38}, {53, 62}, {84, 21}, {96, 19},
+}
+
+// Number of chapters and divisions of the Quran.
+const (
+	ChapterCount = 114
+	JuzCount     = 30
+	HizbCount    = len(hizbStart)
+	ManzilCount  = len(manzilStart)
+)
+
+// division returns number of division starting at starts which contains
+// verse in chapter.
+func division(starts []verse, chapter, verseNo int) int {
+	return sort.Search(len(starts), func(i int) bool {
+		return !starts[i].before(chapter, verseNo)
+	})
+}
+
+// Hizb returns hizb number, from 1 to HizbCount, of verse in chapter.
+func Hizb(chapter, verseNo int) int {
+	return division(hizbStart[:], chapter, verseNo)
+}
+
+// Juz returns juz number, from 1 to JuzCount, of verse in chapter.
+func Juz(chapter, verseNo int) int {
+	return (Hizb(chapter, verseNo) + 1) / 2
+}
+
+// Manzil returns manzil number, from 1 to ManzilCount, of verse in
+// chapter.
+func Manzil(chapter, verseNo int) int {
+	return division(manzilStart[:], chapter, verseNo)
+}
+
+// Sajdah reports whether verse in chapter is a verse of prostration.
+func Sajdah(chapter, verseNo int) bool {
+	for _, v := range sajdah {
+		if v.chapter == chapter && v.verse == verseNo {
+			return true
+		}
+	}
+	return false
+}
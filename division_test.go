@@ -0,0 +1,59 @@
+package lafzi_test
+
+import (
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+)
+
+func TestManzilSajdah(t *testing.T) {
+	tables := []struct {
+		chapter, verse int
+		manzil         int
+		sajdah         bool
+	}{
+		{1, 1, 1, false},
+		{4, 176, 1, false},
+		{5, 1, 2, false},
+		{7, 206, 2, true},
+		{50, 1, 7, false},
+		{96, 19, 7, true},
+		{114, 6, 7, false},
+	}
+
+	for _, table := range tables {
+		if actual := lafzi.Manzil(table.chapter, table.verse); actual != table.manzil {
+			t.Errorf("verse: %d:%d error, expected manzil: %d, actual manzil: %d", table.chapter, table.verse, table.manzil, actual)
+		}
+		if actual := lafzi.Sajdah(table.chapter, table.verse); actual != table.sajdah {
+			t.Errorf("verse: %d:%d error, expected sajdah: %v, actual sajdah: %v", table.chapter, table.verse, table.sajdah, actual)
+		}
+	}
+}
+
+func TestJuzHizb(t *testing.T) {
+	tables := []struct {
+		chapter, verse int
+		juz, hizb      int
+	}{
+		{1, 1, 1, 1},
+		{2, 74, 1, 1},
+		{2, 75, 1, 2},
+		{2, 141, 1, 2},
+		{2, 142, 2, 3},
+		{2, 255, 3, 5},
+		{18, 74, 15, 30},
+		{18, 75, 16, 31},
+		{78, 1, 30, 59},
+		{114, 6, 30, 60},
+	}
+
+	for _, table := range tables {
+		if actual := lafzi.Juz(table.chapter, table.verse); actual != table.juz {
+			t.Errorf("verse: %d:%d error, expected juz: %d, actual juz: %d", table.chapter, table.verse, table.juz, actual)
+		}
+		if actual := lafzi.Hizb(table.chapter, table.verse); actual != table.hizb {
+			t.Errorf("verse: %d:%d error, expected hizb: %d, actual hizb: %d", table.chapter, table.verse, table.hizb, actual)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package lafzi
 
+import "context"
+
 // Document ...
 type Document struct {
 	ID
@@ -11,7 +13,20 @@ type ID = int
 
 // Index ...
 type Index interface {
-	Search(term string, vowel bool) []Document
+	Search(ctx context.Context, term string, vowel bool) ([]Document, error)
+}
+
+// Stats is implemented by Index able to report statistics of indexed
+// documents, it is used to weight trigrams when ranking.
+type Stats interface {
+	// DocumentCount returns the number of indexed documents.
+	DocumentCount(vowel bool) int
+	// DocumentFrequency returns the number of documents containing term.
+	DocumentFrequency(term string, vowel bool) int
+	// DocumentLength returns the number of trigrams of document id.
+	DocumentLength(id ID, vowel bool) int
+	// AverageLength returns the average number of trigrams of documents.
+	AverageLength(vowel bool) float64
 }
 
 // Term ...
@@ -3,9 +3,9 @@ package file
 import (
 	"bufio"
 	"fmt"
+	"io/fs"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -18,18 +18,37 @@ import (
 // Alquran ...
 type Alquran struct {
 	ayat []lafzi.Ayat
+	// ids is ID of every verse of ayat
+	ids map[verseKey]int
+	// translations of languages other than DefaultLanguage by language
+	translations map[string][]string
+	languages    []string
+}
+
+// verseKey is chapter and verse number of a verse.
+type verseKey struct {
+	chapter, verse int
 }
 
 var (
-	totalVerse              = 6236
-	transliterationBasePath = "data/transliteration/"
+	dataDir                 = "data"
+	transliterationBasePath = "transliteration/"
+	mapBasePath             = "map/"
 	generatedMapBasePath    = "data/map/"
 )
 
-// NewAlquran ...
+// NewAlquran returns Alquran of verses in alquranName with translation in
+// DefaultLanguage in translationName, both in format of FormatOf. Verses
+// are in order of alquranName, translation is matched to them by chapter
+// and verse number.
 func NewAlquran(alquranName, translationName string) (*Alquran, error) {
-	var alquran Alquran
-	err := alquran.populate(alquranName, translationName)
+	return NewAlquranFS(osFS{}, alquranName, translationName)
+}
+
+// NewAlquranFS is like NewAlquran but reads files from fsys.
+func NewAlquranFS(fsys fs.FS, alquranName, translationName string) (*Alquran, error) {
+	alquran := Alquran{languages: []string{DefaultLanguage}}
+	err := alquran.populate(fsys, alquranName, translationName)
 	if err != nil {
 		return nil, err
 	}
@@ -37,21 +56,56 @@ func NewAlquran(alquranName, translationName string) (*Alquran, error) {
 	return &alquran, nil
 }
 
-// Ayat ...
+// Ayat returns verse id, it panics if id is out of range.
 func (a *Alquran) Ayat(id int) lafzi.Ayat {
 	return a.ayat[id-1]
 }
 
+// Lookup is like Ayat but returns error if id is out of range.
+func (a *Alquran) Lookup(id int) (lafzi.Ayat, error) {
+	if id < 1 || id > len(a.ayat) {
+		return lafzi.Ayat{}, fmt.Errorf("alquran: id %d out of range 1-%d", id, len(a.ayat))
+	}
+	return a.ayat[id-1], nil
+}
+
+// Verses returns verses of ref in order. Verses of ref must be adjacent
+// in the corpus, though the corpus may be in any order or partial.
+func (a *Alquran) Verses(ref lafzi.Reference) ([]lafzi.Ayat, error) {
+	if _, _, err := ref.IDs(); err != nil {
+		return nil, err
+	}
+	from, to := ref.From, ref.To
+	if from == 0 {
+		from, to = 1, ref.Len()
+	}
+	first, okFirst := a.ids[verseKey{ref.Chapter, from}]
+	last, okLast := a.ids[verseKey{ref.Chapter, to}]
+	if !okFirst || !okLast || last-first != to-from {
+		return nil, fmt.Errorf("alquran: reference %s out of range", ref)
+	}
+	verses := make([]lafzi.Ayat, last-first+1)
+	copy(verses, a.ayat[first-1:last])
+	return verses, nil
+}
+
 // GenerateMap ...
 func (a *Alquran) GenerateMap(transliterationName string) (lettersMapping map[rune]string, err error) {
-	lettersMapping = tryGetMap(transliterationName)
+	return a.GenerateMapFS(os.DirFS(dataDir), transliterationName)
+}
+
+// GenerateMapFS is like GenerateMap but reads map and transliteration
+// directories of fsys such as data.FS, generated map is still written to
+// data/map of the working directory.
+func (a *Alquran) GenerateMapFS(fsys fs.FS, transliterationName string) (lettersMapping map[rune]string, err error) {
+	lettersMapping = tryGetMap(fsys, transliterationName)
 	if lettersMapping != nil {
 		return lettersMapping, nil
 	}
 
 	timeStart := time.Now()
 
-	transliteration, err := os.Open(transliterationBasePath + transliterationName)
+	transliteration, err := fsys.Open(transliterationBasePath + transliterationName)
 	if err != nil {
 		return nil, err
 	}
@@ -134,8 +188,8 @@ func (a *Alquran) GenerateMap(transliterationName string) (lettersMapping map[ru
 	return lettersMapping, nil
 }
 
-func tryGetMap(name string) map[rune]string {
-	generatedMap, err := os.Open(generatedMapBasePath + name)
+func tryGetMap(fsys fs.FS, name string) map[rune]string {
+	generatedMap, err := fsys.Open(mapBasePath + name)
 	if err != nil {
 		return nil
 	}
@@ -157,78 +211,44 @@ func tryGetMap(name string) map[rune]string {
 	return lettersMapping
 }
 
-func (a *Alquran) populate(alquranName, translationName string) error {
-	alquran, err := os.Open(alquranName)
+func (a *Alquran) populate(fsys fs.FS, alquranName, translationName string) error {
+	verses, err := decodeFile(fsys, alquranName)
 	if err != nil {
 		return err
 	}
-	translation, err := os.Open(translationName)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		alquran.Close()
-		translation.Close()
-	}()
-
-	alquranSc := bufio.NewScanner(alquran)
-	translationSc := bufio.NewScanner(translation)
-	ea := errAyat{ayat: make([]lafzi.Ayat, 0, totalVerse)}
-
-	for alquranSc.Scan() && translationSc.Scan() {
-		ea.createAyat(alquranSc.Text(), translationSc.Text())
+	a.ayat = make([]lafzi.Ayat, len(verses))
+	a.ids = make(map[verseKey]int, len(verses))
+	for i, v := range verses {
+		key := verseKey{v.Chapter, v.Verse}
+		if _, ok := a.ids[key]; ok {
+			return fmt.Errorf("corpus %s: verse %d:%d appears twice", alquranName, v.Chapter, v.Verse)
+		}
+		a.ids[key] = i + 1
+		name := v.ChapterName
+		if name == "" {
+			name, _, _ = lafzi.ChapterName(v.Chapter)
+		}
+		a.ayat[i] = lafzi.Ayat{Info: newInfo(v.Chapter, name, v.Verse), Arabic: v.Text}
 	}
 
-	err = alquranSc.Err()
-	if err != nil {
-		return err
-	}
-	err = translationSc.Err()
+	translation, err := a.readTranslation(fsys, translationName)
 	if err != nil {
 		return err
 	}
-	if ea.err != nil {
-		return ea.err
+	for i := range a.ayat {
+		a.ayat[i].Translation = translation[i]
 	}
-
-	a.ayat = ea.ayat
 	return nil
 }
 
-type errAyat struct {
-	ayat []lafzi.Ayat
-	err  error
-}
-
-func (e *errAyat) createAyat(alquran, translation string) {
-	if e.err != nil {
-		return
-	}
-	dataAlquran := strings.Split(alquran, "|")
-	dataTranslation := strings.Split(translation, "|")
-	info, err := newInfo(dataAlquran[:3])
-	e.err = err
-	e.ayat = append(e.ayat, lafzi.Ayat{
-		Info:        info,
-		Arabic:      dataAlquran[3],
-		Translation: dataTranslation[2],
-	})
-}
-
-func newInfo(info []string) (lafzi.Info, error) {
-	chapterNo, err := strconv.Atoi(info[0])
-	if err != nil {
-		return lafzi.Info{}, err
-	}
-	chapterName := info[1]
-	verseNo, err := strconv.Atoi(info[2])
-	if err != nil {
-		return lafzi.Info{}, err
-	}
-
+func newInfo(chapterNo int, chapterName string, verseNo int) lafzi.Info {
 	return lafzi.Info{
 		ChapterNo:   chapterNo,
 		ChapterName: chapterName,
 		VerseNo:     verseNo,
-	}, nil
+		Juz:         lafzi.Juz(chapterNo, verseNo),
+		Hizb:        lafzi.Hizb(chapterNo, verseNo),
+		Manzil:      lafzi.Manzil(chapterNo, verseNo),
+		Sajdah:      lafzi.Sajdah(chapterNo, verseNo),
+	}
 }
@@ -0,0 +1,170 @@
+package file_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/file"
+)
+
+const (
+	testAlquranName     = "../data/quran/uthmani.txt"
+	testTranslationName = "../data/translation/trans-indonesian.txt"
+)
+
+func TestVerses(t *testing.T) {
+	alquran, err := file.NewAlquran(testAlquranName, testTranslationName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ lafzi.Navigator = alquran
+
+	tables := []struct {
+		ref      lafzi.Reference
+		expected []lafzi.ID
+	}{
+		{lafzi.Reference{Chapter: 2, From: 255, To: 255}, []lafzi.ID{262}},
+		{lafzi.Reference{Chapter: 2, From: 1, To: 3}, []lafzi.ID{8, 9, 10}},
+		{lafzi.Reference{Chapter: 114}, []lafzi.ID{6231, 6232, 6233, 6234, 6235, 6236}},
+	}
+
+	for _, table := range tables {
+		verses, err := alquran.Verses(table.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(verses) != len(table.expected) {
+			t.Errorf("reference: %s error, expected: %d verses, actual: %d", table.ref, len(table.expected), len(verses))
+			continue
+		}
+		for i, id := range table.expected {
+			ref, _ := lafzi.ReferenceOf(id)
+			if verses[i].ChapterNo != ref.Chapter || verses[i].VerseNo != ref.From {
+				t.Errorf("reference: %s error, expected: %s, actual: %d:%d", table.ref, ref, verses[i].ChapterNo, verses[i].VerseNo)
+			}
+		}
+	}
+
+	if _, err := alquran.Verses(lafzi.Reference{Chapter: 1, From: 7, To: 8}); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+	for _, id := range []lafzi.ID{0, 6237} {
+		if _, err := alquran.Lookup(id); err == nil {
+			t.Errorf("id: %d error, expected: error, actual: %v", id, err)
+		}
+	}
+	if ayat, err := alquran.Lookup(262); err != nil || ayat.ChapterNo != 2 || ayat.VerseNo != 255 {
+		t.Errorf("id: 262 error, expected: 2:255, actual: %d:%d %v", ayat.ChapterNo, ayat.VerseNo, err)
+	}
+}
+
+func TestVersesPartial(t *testing.T) {
+	corpus := "2|255|a\n2|256|b\n112|1|c\n112|2|d\n112|3|e\n112|4|f\n"
+	dir := writeFiles(t, map[string]string{"quran.txt": corpus, "translation.txt": corpus})
+	defer os.RemoveAll(dir)
+	alquran, err := file.NewAlquran(filepath.Join(dir, "quran.txt"), filepath.Join(dir, "translation.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []struct {
+		ref      lafzi.Reference
+		expected string
+	}{
+		{lafzi.Reference{Chapter: 2, From: 255, To: 256}, "ab"},
+		{lafzi.Reference{Chapter: 2, From: 256, To: 256}, "b"},
+		{lafzi.Reference{Chapter: 112}, "cdef"},
+		{lafzi.Reference{Chapter: 2}, ""},
+		{lafzi.Reference{Chapter: 2, From: 1, To: 2}, ""},
+		{lafzi.Reference{Chapter: 2, From: 256, To: 257}, ""},
+	}
+
+	for _, table := range tables {
+		verses, err := alquran.Verses(table.ref)
+		if table.expected == "" {
+			if err == nil {
+				t.Errorf("reference: %s error, expected: error, actual: %v", table.ref, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		for _, v := range verses {
+			actual += v.Arabic
+		}
+		if actual != table.expected {
+			t.Errorf("reference: %s error, expected: %s, actual: %s", table.ref, table.expected, actual)
+		}
+	}
+}
+
+func TestAddTranslation(t *testing.T) {
+	alquran, err := file.NewAlquran(testAlquranName, testTranslationName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ lafzi.Translator = alquran
+	b, err := ioutil.ReadFile(testTranslationName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	// every verse translated as its reference, in reverse order
+	var reversed []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		data := strings.SplitN(lines[i], "|", 3)
+		reversed = append(reversed, fmt.Sprintf("%s|%s|%s:%s", data[0], data[1], data[0], data[1]))
+	}
+	dir := writeFiles(t, map[string]string{
+		"ref.txt":       strings.Join(reversed, "\n"),
+		"missing.txt":   strings.Join(reversed[1:], "\n"),
+		"twice.txt":     strings.Join(append(reversed, reversed[0]), "\n"),
+		"outrange.txt":  strings.Join(append(reversed, "1|8|x"), "\n"),
+		"malformed.txt": "1|1",
+	})
+
+	for _, name := range []string{"missing.txt", "twice.txt", "outrange.txt", "malformed.txt"} {
+		if err := alquran.AddTranslation("xx", filepath.Join(dir, name)); err == nil {
+			t.Errorf("translation: %s error, expected: error, actual: %v", name, err)
+		}
+	}
+	if err := alquran.AddTranslation("ref", filepath.Join(dir, "ref.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := alquran.AddTranslation(file.DefaultLanguage, filepath.Join(dir, "ref.txt")); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+
+	if expected, actual := []string{file.DefaultLanguage, "ref"}, alquran.Languages(); strings.Join(expected, ",") != strings.Join(actual, ",") {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	tables := []struct {
+		id       lafzi.ID
+		lang     string
+		expected string
+	}{
+		{262, "ref", "2:255"},
+		{6236, "ref", "114:6"},
+		{1, "", alquran.Ayat(1).Translation},
+		{1, file.DefaultLanguage, alquran.Ayat(1).Translation},
+	}
+	for _, table := range tables {
+		actual, err := alquran.Translation(table.id, table.lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != table.expected {
+			t.Errorf("id: %d lang: %s error, expected: %s, actual: %s", table.id, table.lang, table.expected, actual)
+		}
+	}
+	if _, err := alquran.Translation(1, "xx"); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/billyzaelani/go-lafzi/pkg/index"
+	"github.com/billyzaelani/go-lafzi/pkg/indexer"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/arabic"
+)
+
+// Names of files written by Build.
+const (
+	ManifestName      = "manifest.json"
+	PhoneticVowelName = "phonetic_vowel.txt"
+	PhoneticName      = "phonetic.txt"
+	IndexVowelName    = "index_vowel.lfz"
+	IndexName         = "index.lfz"
+)
+
+// Manifest describes index directory generated by Build.
+type Manifest struct {
+	Format    int       `json:"format"`
+	Corpus    string    `json:"corpus"`
+	Script    string    `json:"script"`
+	Checksum  string    `json:"checksum"`
+	Documents int       `json:"documents"`
+	Created   time.Time `json:"created"`
+	Vowel     Built     `json:"vowel"`
+	NonVowel  Built     `json:"nonvowel"`
+}
+
+// Built describes phonetic corpus and index of one encoding mode.
+type Built struct {
+	Phonetic string `json:"phonetic"`
+	Index    string `json:"index"`
+	Terms    int    `json:"terms"`
+}
+
+// Build encodes alquranName in format of FormatOf written in letters mode
+// with and without harakat, builds both indexes and writes them along
+// with the phonetic corpora and manifest into dir. Verse ID is its order
+// in alquranName, the same as NewAlquran.
+func Build(alquranName, dir string, mode arabic.LettersMode) (*Manifest, error) {
+	corpus, err := ioutil.ReadFile(alquranName)
+	if err != nil {
+		return nil, err
+	}
+	verses, err := decodeVerses(alquranName, bytes.NewReader(corpus))
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(corpus)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	enc := arabic.NewEncoder(mode)
+
+	var phoneticV, phoneticN bytes.Buffer
+	bv, bn := indexer.NewBuilder(), indexer.NewBuilder()
+	for i, v := range verses {
+		id := i + 1
+		pv := enc.Encode([]byte(v.Text), true)
+		pn := enc.Encode([]byte(v.Text), false)
+		bv.Add(id, pv)
+		bn.Add(id, pn)
+		fmt.Fprintf(&phoneticV, "%d|%s\n", id, pv)
+		fmt.Fprintf(&phoneticN, "%d|%s\n", id, pn)
+	}
+
+	m := &Manifest{
+		Format:    index.Version,
+		Corpus:    alquranName,
+		Script:    mode.String(),
+		Checksum:  hex.EncodeToString(checksum[:]),
+		Documents: len(verses),
+		Created:   time.Now().UTC(),
+		Vowel: Built{
+			Phonetic: PhoneticVowelName,
+			Index:    IndexVowelName,
+			Terms:    bv.TermCount(),
+		},
+		NonVowel: Built{
+			Phonetic: PhoneticName,
+			Index:    IndexName,
+			Terms:    bn.TermCount(),
+		},
+	}
+
+	writes := []struct {
+		name string
+		w    io.WriterTo
+	}{
+		{PhoneticVowelName, &phoneticV},
+		{PhoneticName, &phoneticN},
+		{IndexVowelName, bv},
+		{IndexName, bn},
+	}
+	for _, write := range writes {
+		if err := writeFile(filepath.Join(dir, write.name), write.w); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	err = writeFile(filepath.Join(dir, ManifestName), bytes.NewBuffer(append(b, '\n')))
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func writeFile(name string, wt io.WriterTo) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if _, err := wt.WriteTo(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadManifest reads manifest of index directory dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	return ReadManifestFS(osFS{}, dir)
+}
+
+// ReadManifestFS is like ReadManifest but reads dir of fsys.
+func ReadManifestFS(fsys fs.FS, dir string) (*Manifest, error) {
+	b, err := fs.ReadFile(fsys, path.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// NewIndexDir opens index directory dir generated by Build.
+func NewIndexDir(dir string) (*Index, error) {
+	return NewIndexDirFS(osFS{}, dir)
+}
+
+// NewIndexDirFS is like NewIndexDir but reads dir of fsys such as
+// data.FS.
+func NewIndexDirFS(fsys fs.FS, dir string) (*Index, error) {
+	m, err := ReadManifestFS(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	if m.Format != index.Version {
+		return nil, index.ErrVersion
+	}
+
+	return NewIndexFS(fsys, path.Join(dir, m.Vowel.Index), path.Join(dir, m.NonVowel.Index))
+}
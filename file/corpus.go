@@ -0,0 +1,178 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Verse is text of a verse in a corpus file, either the Quran or its
+// translation. ChapterName is empty if the file has no chapter names.
+type Verse struct {
+	Chapter     int    `json:"chapter"`
+	ChapterName string `json:"name,omitempty"`
+	Verse       int    `json:"verse"`
+	Text        string `json:"text"`
+}
+
+// Format decodes verses of a corpus file.
+type Format interface {
+	Decode(r io.Reader) ([]Verse, error)
+}
+
+// Formats of corpus files.
+var (
+	// Pipe is lines of chapter|name|verse|text such as
+	// data/quran/uthmani.txt, or chapter|verse|text such as
+	// data/translation/trans-indonesian.txt and Tanzil text files. Empty
+	// lines and lines starting with # are skipped.
+	Pipe Format = pipeFormat{}
+	// TanzilXML is Tanzil quran text or translation XML such as
+	// quran-uthmani.xml, see http://tanzil.net/download.
+	TanzilXML Format = xmlFormat{}
+	// JSON is an array of verses such as
+	//
+	//	[{"chapter": 1, "name": "Al-Fatihah", "verse": 1, "text": "..."}]
+	//
+	// where name is optional.
+	JSON Format = jsonFormat{}
+)
+
+// FormatOf returns format of file name by its extension, TanzilXML for
+// .xml, JSON for .json and Pipe otherwise.
+func FormatOf(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xml":
+		return TanzilXML
+	case ".json":
+		return JSON
+	default:
+		return Pipe
+	}
+}
+
+// decodeFile decodes verses of file name of fsys in its format.
+func decodeFile(fsys fs.FS, name string) ([]Verse, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeVerses(name, f)
+}
+
+// decodeVerses decodes verses of r read from file name in its format.
+func decodeVerses(name string, r io.Reader) ([]Verse, error) {
+	verses, err := FormatOf(name).Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("corpus %s: %v", name, err)
+	}
+	if len(verses) == 0 {
+		return nil, fmt.Errorf("corpus %s: no verses", name)
+	}
+	for _, v := range verses {
+		if v.Chapter < 1 || v.Verse < 1 {
+			return nil, fmt.Errorf("corpus %s: invalid verse %d:%d", name, v.Chapter, v.Verse)
+		}
+	}
+	return verses, nil
+}
+
+// osFS opens files by path of the operating system, unlike os.DirFS it
+// accepts absolute paths and paths out of the working directory.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+type pipeFormat struct{}
+
+func (pipeFormat) Decode(r io.Reader) ([]Verse, error) {
+	var verses []Verse
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		v, err := parsePipe(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		verses = append(verses, v)
+	}
+	return verses, sc.Err()
+}
+
+// parsePipe parses line chapter|name|verse|text, or chapter|verse|text if
+// the second field is a number.
+func parsePipe(line string) (Verse, error) {
+	var v Verse
+	data := strings.SplitN(line, "|", 4)
+	if len(data) < 3 {
+		return v, fmt.Errorf("invalid line %q", line)
+	}
+	var err error
+	if v.Chapter, err = strconv.Atoi(data[0]); err != nil {
+		return v, fmt.Errorf("invalid chapter %q", data[0])
+	}
+	if v.Verse, err = strconv.Atoi(data[1]); err == nil {
+		v.Text = strings.Join(data[2:], "|")
+		return v, nil
+	}
+	if len(data) < 4 {
+		return v, fmt.Errorf("invalid line %q", line)
+	}
+	v.ChapterName = data[1]
+	if v.Verse, err = strconv.Atoi(data[2]); err != nil {
+		return v, fmt.Errorf("invalid verse %q", data[2])
+	}
+	v.Text = data[3]
+	return v, nil
+}
+
+// tanzilText is Tanzil quran text or translation. Name of chapter is in
+// arabic script, so it is not kept.
+type tanzilText struct {
+	Suras []struct {
+		Index int `xml:"index,attr"`
+		Ayas  []struct {
+			Index int    `xml:"index,attr"`
+			Text  string `xml:"text,attr"`
+		} `xml:"aya"`
+	} `xml:"sura"`
+}
+
+type xmlFormat struct{}
+
+func (xmlFormat) Decode(r io.Reader) ([]Verse, error) {
+	var t tanzilText
+	if err := xml.NewDecoder(r).Decode(&t); err != nil {
+		return nil, err
+	}
+	var verses []Verse
+	for _, sura := range t.Suras {
+		for _, aya := range sura.Ayas {
+			verses = append(verses, Verse{Chapter: sura.Index, Verse: aya.Index, Text: aya.Text})
+		}
+	}
+	return verses, nil
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Decode(r io.Reader) ([]Verse, error) {
+	var verses []Verse
+	if err := json.NewDecoder(r).Decode(&verses); err != nil {
+		return nil, err
+	}
+	return verses, nil
+}
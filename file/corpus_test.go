@@ -0,0 +1,128 @@
+package file_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/file"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/arabic"
+)
+
+func TestFormat(t *testing.T) {
+	verses := []file.Verse{
+		{Chapter: 1, ChapterName: "Al-Fatihah", Verse: 1, Text: "a|b"},
+		{Chapter: 2, Verse: 255, Text: "c"},
+	}
+	tables := []struct {
+		name     string
+		input    string
+		expected []file.Verse
+	}{
+		{"quran.txt", "1|Al-Fatihah|1|a|b\n\n# comment\n2|255|c\n", verses},
+		{"quran.json", `[{"chapter": 1, "name": "Al-Fatihah", "verse": 1, "text": "a|b"},
+			{"chapter": 2, "verse": 255, "text": "c"}]`, verses},
+		{"quran.xml", `<?xml version="1.0" encoding="utf-8" ?>
+<quran>
+	<sura index="1" name="الفاتحة"><aya index="1" text="a|b" /></sura>
+	<sura index="2" name="البقرة"><aya index="255" text="c" bismillah="d" /></sura>
+</quran>`, []file.Verse{{Chapter: 1, Verse: 1, Text: "a|b"}, {Chapter: 2, Verse: 255, Text: "c"}}},
+	}
+
+	for _, table := range tables {
+		actual, err := file.FormatOf(table.name).Decode(strings.NewReader(table.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(table.expected, actual) {
+			t.Errorf("format: %s error, expected: %+v, actual: %+v", table.name, table.expected, actual)
+		}
+	}
+}
+
+func TestNewAlquranFormat(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"quran.xml": `<quran>
+	<sura index="1" name="الفاتحة">
+		<aya index="1" text="a" /><aya index="2" text="b" />
+	</sura>
+	<sura index="2" name="البقرة"><aya index="1" text="c" bismillah="x" /></sura>
+</quran>`,
+		"translation.json": `[{"chapter": 2, "verse": 1, "text": "C"},
+			{"chapter": 1, "verse": 2, "text": "B"}, {"chapter": 1, "verse": 1, "text": "A"}]`,
+		"twice.txt":   "1|A|1|a\n1|A|1|b\n",
+		"unknown.txt": "1|1|A\n1|2|B\n2|1|C\n3|1|D\n",
+	})
+	defer os.RemoveAll(dir)
+
+	alquran, err := file.NewAlquran(filepath.Join(dir, "quran.xml"), filepath.Join(dir, "translation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []struct {
+		id       int
+		expected lafzi.Ayat
+	}{
+		{1, lafzi.Ayat{Info: lafzi.Info{ChapterNo: 1, ChapterName: "Al-Fatihah", VerseNo: 1, Juz: 1, Hizb: 1, Manzil: 1},
+			Arabic: "a", Translation: "A"}},
+		{3, lafzi.Ayat{Info: lafzi.Info{ChapterNo: 2, ChapterName: "Al-Baqarah", VerseNo: 1, Juz: 1, Hizb: 1, Manzil: 1},
+			Arabic: "c", Translation: "C"}},
+	}
+	for _, table := range tables {
+		if actual := alquran.Ayat(table.id); actual != table.expected {
+			t.Errorf("id: %d error, expected: %+v, actual: %+v", table.id, table.expected, actual)
+		}
+	}
+
+	if _, err := file.NewAlquran(filepath.Join(dir, "twice.txt"), filepath.Join(dir, "translation.json")); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+	if err := alquran.AddTranslation("xx", filepath.Join(dir, "unknown.txt")); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
+
+func TestBuildFormat(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"quran.txt": "# Al-Fatihah\n1|1|بِسْمِ اللَّهِ\n\n1|2|الْحَمْدُ لِلَّهِ\n112|1|قُلْ هُوَ اللَّهُ أَحَدٌ\n",
+		"quran.json": `[{"chapter": 1, "verse": 1, "text": "بِسْمِ اللَّهِ"},
+			{"chapter": 1, "verse": 2, "text": "الْحَمْدُ لِلَّهِ"},
+			{"chapter": 112, "verse": 1, "text": "قُلْ هُوَ اللَّهُ أَحَدٌ"}]`,
+		"quran.xml": `<quran>
+	<sura index="1" name="الفاتحة">
+		<aya index="1" text="بِسْمِ اللَّهِ" /><aya index="2" text="الْحَمْدُ لِلَّهِ" />
+	</sura>
+	<sura index="112" name="الإخلاص"><aya index="1" text="قُلْ هُوَ اللَّهُ أَحَدٌ" /></sura>
+</quran>`,
+	})
+	defer os.RemoveAll(dir)
+
+	var expected []byte
+	for _, name := range []string{"quran.txt", "quran.json", "quran.xml"} {
+		out := filepath.Join(dir, strings.TrimPrefix(filepath.Ext(name), "."))
+		m, err := file.Build(filepath.Join(dir, name), out, arabic.LettersSimple)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Documents != 3 {
+			t.Errorf("corpus: %s error, expected: %d documents, actual: %d", name, 3, m.Documents)
+		}
+		actual, err := ioutil.ReadFile(filepath.Join(out, file.PhoneticName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected == nil {
+			expected = actual
+		} else if !bytes.Equal(expected, actual) {
+			t.Errorf("corpus: %s error, expected: %s, actual: %s", name, expected, actual)
+		}
+	}
+	if !bytes.HasPrefix(expected, []byte("1|BSMLH\n2|")) || !bytes.Contains(expected, []byte("\n3|")) {
+		t.Errorf("expected: verse ID by order, actual: %s", expected)
+	}
+}
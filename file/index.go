@@ -1,182 +1,163 @@
 package file
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"io"
-	"os"
-	"strconv"
-	"strings"
+	"io/fs"
+	"io/ioutil"
 
 	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/pkg/index"
 )
 
 // Index ...
 type Index struct {
-	termlistV, termlistN map[string]line
-	postlistV, postlistN ReaderAtCloser
+	indexV, indexN *index.Reader
+	fileV, fileN   io.Closer
+	statsV, statsN stats
 }
 
-type line struct {
-	offset, n int64
+// stats are length of documents in one encoding mode.
+type stats struct {
+	lengths map[lafzi.ID]int
+	avg     float64
 }
 
-// ReaderAtCloser is wrapper between io.ReaderAt and io.Closer
-type ReaderAtCloser interface {
-	io.ReaderAt
-	io.Closer
+// NewIndex opens binary index files generated by cmd/generateindex.
+func NewIndex(indexV, indexN string) (*Index, error) {
+	return NewIndexFS(osFS{}, indexV, indexN)
 }
 
-// NewIndex ...
-func NewIndex(termlistV, termlistN string,
-	postlistV, postlistN string) (*Index, error) {
-	tv, err := parseTermlist(termlistV)
+// NewIndexFS is like NewIndex but reads files from fsys.
+func NewIndexFS(fsys fs.FS, indexV, indexN string) (*Index, error) {
+	fv, rv, err := openIndex(fsys, indexV)
 	if err != nil {
 		return nil, err
 	}
-	tn, err := parseTermlist(termlistN)
+	fn, rn, err := openIndex(fsys, indexN)
 	if err != nil {
+		fv.Close()
 		return nil, err
 	}
-
-	pvFile, err := os.Open(postlistV)
+	sv, err := readStats(rv)
 	if err != nil {
+		fv.Close()
+		fn.Close()
 		return nil, err
 	}
-	pnFile, err := os.Open(postlistN)
+	sn, err := readStats(rn)
 	if err != nil {
+		fv.Close()
+		fn.Close()
 		return nil, err
 	}
 
 	return &Index{
-		termlistV: tv,
-		termlistN: tn,
-		postlistV: pvFile,
-		postlistN: pnFile,
+		indexV: rv,
+		indexN: rn,
+		fileV:  fv,
+		fileN:  fn,
+		statsV: sv,
+		statsN: sn,
 	}, nil
 }
 
-// Search ...
-func (idx *Index) Search(term string, v bool) (docs []lafzi.Document) {
-	termlist, postlist := idx.populateList(v)
-	if occurs, ok := termlist[term]; ok {
-		// retrieve posting list based on term
-		byteOccur := seek(postlist, occurs.offset, occurs.n)
-		occur := string(byteOccur[:])
-		matchedPostlist := strings.Split(occur, ";")
-
-		for _, data := range matchedPostlist {
-			docID, termPos, err := parsePostlist(data)
-			if err == nil {
-				docs = append(docs, lafzi.Document{
-					ID:   docID,
-					Term: termPos,
-				})
-			}
+func readStats(r *index.Reader) (stats, error) {
+	lengths, err := r.Lengths()
+	if err != nil {
+		return stats{}, err
+	}
+	s := stats{lengths: lengths}
+	if len(lengths) > 0 {
+		var sum int
+		for _, l := range lengths {
+			sum += l
 		}
+		s.avg = float64(sum) / float64(len(lengths))
 	}
-	return
+	return s, nil
 }
 
-// Close ...
-func (idx *Index) Close() {
-	idx.postlistV.Close()
-	idx.postlistN.Close()
-}
-
-func (idx *Index) populateList(v bool) (map[string]line, ReaderAtCloser) {
-	if v {
-		return idx.termlistV, idx.postlistV
+func openIndex(fsys fs.FS, name string) (io.Closer, *index.Reader, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
 	}
-	return idx.termlistN, idx.postlistN
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		// index is read at random offsets, read it whole if f cannot
+		b, err := ioutil.ReadAll(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		ra = bytes.NewReader(b)
+	}
+	r, err := index.NewReader(ra)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, r, nil
 }
 
-func parseTermlist(termlistFilename string) (map[string]line, error) {
-	tFile, err := os.Open(termlistFilename)
-	if err != nil {
+// Search ...
+func (idx *Index) Search(ctx context.Context, term string, v bool) ([]lafzi.Document, error) {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	defer tFile.Close()
-	stat, err := tFile.Stat()
+	postings, err := idx.reader(v).Postings(term)
 	if err != nil {
 		return nil, err
 	}
-	// scan termlist, place it to memory
-	sc := bufio.NewScanner(tFile)
-	var prevToken string
-	var prevOffset int64
-
-	terms := make(map[string]line)
-	for sc.Scan() {
-		str := strings.Split(sc.Text(), "|")
-		token := string(str[0])
-		i, err := strconv.Atoi(str[1])
-		if err != nil {
-			return nil, err
-		}
-		offset := int64(i)
-		if prevToken != "" {
-			n := (offset - prevOffset) - 1
-			terms[prevToken] = line{prevOffset, n}
+	docs := make([]lafzi.Document, len(postings))
+	for i, p := range postings {
+		docs[i] = lafzi.Document{
+			ID:   p.ID,
+			Term: p.Positions,
 		}
-		prevOffset = offset
-		prevToken = token
 	}
-	if sc.Err() != nil {
-		return nil, err
-	}
-	// last line
-	terms[prevToken] = line{prevOffset, stat.Size() - 1}
-	return terms, nil
+	return docs, nil
 }
 
-func parsePostlist(str string) (id int, termPos []int, err error) {
-	data := strings.Split(str, ":")
-	id, err = strconv.Atoi(data[0])
-	if err != nil {
-		return 0, nil, err
-	}
-	termPos, err = multipleAtoI(data[1], ",")
-	if err != nil {
-		return 0, nil, err
-	}
+// DocumentCount ...
+func (idx *Index) DocumentCount(v bool) int {
+	return idx.reader(v).DocumentCount()
+}
 
-	return
+// DocumentFrequency ...
+func (idx *Index) DocumentFrequency(term string, v bool) int {
+	return idx.reader(v).DocumentFrequency(term)
 }
 
-// seek reads r form offset to sectionLength.
-// TODO: seek might be not concurrency-safe, need further research
-func seek(r io.ReaderAt, offset int64, sectionLength int64) []byte {
-	section := io.NewSectionReader(r, offset, sectionLength)
-	p := make([]byte, sectionLength)
-	n, _ := section.Read(p)
-	// TODO: handle error
-	// if err != io.EOF {
-	// 	return p[:n], err
-	// }
-	return p[:n]
+// DocumentLength ...
+func (idx *Index) DocumentLength(id lafzi.ID, v bool) int {
+	return idx.stats(v).lengths[id]
 }
 
-func multipleAtoI(str, sep string) ([]int, error) {
-	in := strings.Split(str, sep)
-	out := errAtoi{ints: make([]int, 0, len(in))}
-	for _, numStr := range in {
-		out.atoi(numStr)
-	}
-	return out.ints, out.err
+// AverageLength ...
+func (idx *Index) AverageLength(v bool) float64 {
+	return idx.stats(v).avg
+}
+
+// Close ...
+func (idx *Index) Close() {
+	idx.fileV.Close()
+	idx.fileN.Close()
 }
 
-type errAtoi struct {
-	ints []int
-	err  error
+func (idx *Index) reader(v bool) *index.Reader {
+	if v {
+		return idx.indexV
+	}
+	return idx.indexN
 }
 
-func (e *errAtoi) atoi(str string) {
-	if e.err != nil {
-		// reset ints
-		e.ints = nil
-		return
+func (idx *Index) stats(v bool) *stats {
+	if v {
+		return &idx.statsV
 	}
-	num, err := strconv.Atoi(str)
-	e.ints = append(e.ints, num)
-	e.err = err
+	return &idx.statsN
 }
@@ -0,0 +1,123 @@
+package file
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+)
+
+// metadata is Tanzil quran metadata, see http://tanzil.net/docs/quran_metadata.
+type metadata struct {
+	Suras    []metaSura `xml:"suras>sura"`
+	Juzs     []metaMark `xml:"juzs>juz"`
+	Quarters []metaMark `xml:"hizbs>quarter"`
+	Manzils  []metaMark `xml:"manzils>manzil"`
+	Rukus    []metaMark `xml:"rukus>ruku"`
+	Pages    []metaMark `xml:"pages>page"`
+	Sajdas   []metaMark `xml:"sajdas>sajda"`
+}
+
+type metaSura struct {
+	Index int    `xml:"index,attr"`
+	Ayas  int    `xml:"ayas,attr"`
+	Type  string `xml:"type,attr"`
+	Order int    `xml:"order,attr"`
+}
+
+// metaMark is a verse where division Index starts, or a verse of sajdah.
+type metaMark struct {
+	Index int `xml:"index,attr"`
+	Sura  int `xml:"sura,attr"`
+	Aya   int `xml:"aya,attr"`
+}
+
+// LoadMetadata sets Info of every verse from Tanzil metadata file such as
+// quran-data.xml of tanzil.net, replacing juz, hizb, manzil and sajdah
+// known without it. It returns error if chapters of metadata do not
+// match the verses.
+func (a *Alquran) LoadMetadata(name string) error {
+	return a.LoadMetadataFS(osFS{}, name)
+}
+
+// LoadMetadataFS is like LoadMetadata but reads file from fsys.
+func (a *Alquran) LoadMetadataFS(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var m metadata
+	if err := xml.NewDecoder(f).Decode(&m); err != nil {
+		return fmt.Errorf("metadata %s: %v", name, err)
+	}
+	if err := a.setMetadata(&m); err != nil {
+		return fmt.Errorf("metadata %s: %v", name, err)
+	}
+	return nil
+}
+
+func (a *Alquran) setMetadata(m *metadata) error {
+	suras := make(map[int]metaSura, len(m.Suras))
+	var total int
+	for _, s := range m.Suras {
+		suras[s.Index] = s
+		total += s.Ayas
+	}
+	if total != len(a.ayat) {
+		return fmt.Errorf("%d verses, expected %d", total, len(a.ayat))
+	}
+	for _, ayat := range a.ayat {
+		if s, ok := suras[ayat.ChapterNo]; !ok || ayat.VerseNo > s.Ayas {
+			return fmt.Errorf("verse %d:%d not in metadata", ayat.ChapterNo, ayat.VerseNo)
+		}
+	}
+
+	for i := range a.ayat {
+		info := &a.ayat[i].Info
+		s := suras[info.ChapterNo]
+		info.Revelation = s.Type
+		info.RevelationOrder = s.Order
+		info.Sajdah = false
+	}
+
+	a.setDivision(m.Juzs, func(info *lafzi.Info, n int) { info.Juz = n })
+	a.setDivision(m.Quarters, func(info *lafzi.Info, n int) {
+		info.Quarter = n
+		info.Hizb = (n + 3) / 4
+	})
+	a.setDivision(m.Manzils, func(info *lafzi.Info, n int) { info.Manzil = n })
+	a.setDivision(m.Rukus, func(info *lafzi.Info, n int) { info.Ruku = n })
+	a.setDivision(m.Pages, func(info *lafzi.Info, n int) { info.Page = n })
+	for _, s := range m.Sajdas {
+		for i := range a.ayat {
+			if info := &a.ayat[i].Info; info.ChapterNo == s.Sura && info.VerseNo == s.Aya {
+				info.Sajdah = true
+			}
+		}
+	}
+	return nil
+}
+
+// setDivision sets number of division starting at marks to every verse
+// using set, it does nothing if marks is empty.
+func (a *Alquran) setDivision(marks []metaMark, set func(info *lafzi.Info, n int)) {
+	if len(marks) == 0 {
+		return
+	}
+	sort.Slice(marks, func(i, j int) bool {
+		return marks[i].Index < marks[j].Index
+	})
+	var k int
+	for i := range a.ayat {
+		info := &a.ayat[i].Info
+		for k+1 < len(marks) && (marks[k+1].Sura < info.ChapterNo ||
+			marks[k+1].Sura == info.ChapterNo && marks[k+1].Aya <= info.VerseNo) {
+			k++
+		}
+		set(info, marks[k].Index)
+	}
+}
@@ -0,0 +1,97 @@
+package file_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/file"
+)
+
+// testMetadata is metadata of a Quran of two chapters, of 3 and 2 verses.
+const testMetadata = `<?xml version="1.0" encoding="utf-8" ?>
+<quran type="metadata">
+	<suras alias="chapters">
+		<sura index="1" ayas="3" start="0" name="" tname="A" ename="A" type="Meccan" order="5" rukus="1" />
+		<sura index="2" ayas="2" start="3" name="" tname="B" ename="B" type="Medinan" order="87" rukus="1" />
+	</suras>
+	<juzs alias="parts">
+		<juz index="1" sura="1" aya="1" />
+		<juz index="2" sura="2" aya="2" />
+	</juzs>
+	<hizbs alias="groups">
+		<quarter index="1" sura="1" aya="1" />
+		<quarter index="2" sura="1" aya="3" />
+		<quarter index="5" sura="2" aya="2" />
+	</hizbs>
+	<manzils alias="stations">
+		<manzil index="1" sura="1" aya="1" />
+	</manzils>
+	<rukus alias="sections">
+		<ruku index="1" sura="1" aya="1" />
+		<ruku index="2" sura="2" aya="1" />
+	</rukus>
+	<pages>
+		<page index="1" sura="1" aya="1" />
+		<page index="2" sura="1" aya="2" />
+	</pages>
+	<sajdas>
+		<sajda index="1" sura="2" aya="1" type="recommended" />
+	</sajdas>
+</quran>
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "lafzi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMetadata(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"quran.txt":       "1|A|1|a\n1|A|2|b\n1|A|3|c\n2|B|1|d\n2|B|2|e\n",
+		"translation.txt": "1|1|a\n1|2|b\n1|3|c\n2|1|d\n2|2|e\n",
+		"metadata.xml":    testMetadata,
+		"invalid.xml":     `<quran><suras><sura index="1" ayas="3" /></suras></quran>`,
+	})
+	defer os.RemoveAll(dir)
+
+	alquran, err := file.NewAlquran(filepath.Join(dir, "quran.txt"), filepath.Join(dir, "translation.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alquran.LoadMetadata(filepath.Join(dir, "metadata.xml")); err != nil {
+		t.Fatal(err)
+	}
+	tables := []struct {
+		id       int
+		expected lafzi.Info
+	}{
+		{1, lafzi.Info{ChapterNo: 1, ChapterName: "A", VerseNo: 1, Juz: 1, Hizb: 1, Quarter: 1, Manzil: 1,
+			Page: 1, Ruku: 1, Revelation: "Meccan", RevelationOrder: 5}},
+		{3, lafzi.Info{ChapterNo: 1, ChapterName: "A", VerseNo: 3, Juz: 1, Hizb: 1, Quarter: 2, Manzil: 1,
+			Page: 2, Ruku: 1, Revelation: "Meccan", RevelationOrder: 5}},
+		{4, lafzi.Info{ChapterNo: 2, ChapterName: "B", VerseNo: 1, Juz: 1, Hizb: 1, Quarter: 2, Manzil: 1,
+			Page: 2, Ruku: 2, Sajdah: true, Revelation: "Medinan", RevelationOrder: 87}},
+		{5, lafzi.Info{ChapterNo: 2, ChapterName: "B", VerseNo: 2, Juz: 2, Hizb: 2, Quarter: 5, Manzil: 1,
+			Page: 2, Ruku: 2, Revelation: "Medinan", RevelationOrder: 87}},
+	}
+	for _, table := range tables {
+		if actual := alquran.Ayat(table.id).Info; actual != table.expected {
+			t.Errorf("id: %d error, expected: %+v, actual: %+v", table.id, table.expected, actual)
+		}
+	}
+
+	if err := alquran.LoadMetadata(filepath.Join(dir, "invalid.xml")); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package file
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// DefaultLanguage is language of translation given to NewAlquran.
+const DefaultLanguage = "id"
+
+// AddTranslation adds translation in lang from file name in format of
+// FormatOf, such as data/translation/trans-indonesian.txt. Every verse
+// must be translated once, in any order.
+func (a *Alquran) AddTranslation(lang, name string) error {
+	return a.AddTranslationFS(osFS{}, lang, name)
+}
+
+// AddTranslationFS is like AddTranslation but reads file from fsys.
+func (a *Alquran) AddTranslationFS(fsys fs.FS, lang, name string) error {
+	if lang == "" {
+		return fmt.Errorf("translation %s: empty language", name)
+	}
+	for _, l := range a.languages {
+		if l == lang {
+			return fmt.Errorf("translation %s: language %q already added", name, lang)
+		}
+	}
+
+	translation, err := a.readTranslation(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	if a.translations == nil {
+		a.translations = make(map[string][]string)
+	}
+	a.translations[lang] = translation
+	a.languages = append(a.languages, lang)
+	return nil
+}
+
+// readTranslation returns translation of every verse in order of ayat
+// from file name in format of FormatOf. Every verse must be translated
+// once, in any order.
+func (a *Alquran) readTranslation(fsys fs.FS, name string) ([]string, error) {
+	verses, err := decodeFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	translation := make([]string, len(a.ayat))
+	translated := make([]bool, len(a.ayat))
+	for _, v := range verses {
+		id, ok := a.ids[verseKey{v.Chapter, v.Verse}]
+		if !ok {
+			return nil, fmt.Errorf("translation %s: verse %d:%d not in the Quran", name, v.Chapter, v.Verse)
+		}
+		if translated[id-1] {
+			return nil, fmt.Errorf("translation %s: verse %d:%d translated twice", name, v.Chapter, v.Verse)
+		}
+		translation[id-1], translated[id-1] = v.Text, true
+	}
+	for i, ok := range translated {
+		if !ok {
+			info := a.ayat[i].Info
+			return nil, fmt.Errorf("translation %s: verse %d:%d not translated", name, info.ChapterNo, info.VerseNo)
+		}
+	}
+	return translation, nil
+}
+
+// Languages returns DefaultLanguage followed by language of every added
+// translation.
+func (a *Alquran) Languages() []string {
+	return append([]string(nil), a.languages...)
+}
+
+// Translation returns translation of verse id in lang, empty lang is
+// DefaultLanguage.
+func (a *Alquran) Translation(id int, lang string) (string, error) {
+	ayat, err := a.Lookup(id)
+	if err != nil {
+		return "", err
+	}
+	if lang == "" || lang == DefaultLanguage {
+		return ayat.Translation, nil
+	}
+	translation, ok := a.translations[lang]
+	if !ok {
+		return "", fmt.Errorf("alquran: no translation in %q", lang)
+	}
+	return translation[id-1], nil
+}
@@ -1,6 +1,6 @@
 module github.com/billyzaelani/go-lafzi
 
-go 1.14
+go 1.16
 
 require (
 	github.com/dlclark/regexp2 v1.2.0
@@ -1,14 +1,20 @@
 package http
 
 import (
+	"io/fs"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func asset(r *mux.Router) {
-	r.NewRoute().
-		Methods("GET").
-		PathPrefix("/asset/").
-		Handler(http.StripPrefix("/asset/", http.FileServer(http.Dir("web/asset/"))))
+// Asset serves asset directory of fsys such as web.FS under /asset/.
+func Asset(fsys fs.FS) Service {
+	// Sub only fails on invalid directory name
+	assets, _ := fs.Sub(fsys, "asset")
+	return func(r *mux.Router) {
+		r.NewRoute().
+			Methods("GET").
+			PathPrefix("/asset/").
+			Handler(http.StripPrefix("/asset/", http.FileServer(http.FS(assets))))
+	}
 }
@@ -1,16 +1,24 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/billyzaelani/go-lafzi/search"
 	t "github.com/billyzaelani/go-lafzi/web/template"
 	"github.com/gorilla/mux"
 )
 
-// Search ...
-func Search(s search.Service) Service {
-	handler := &searchHandler{s}
+// Search serves search of s with options of request overriding defaults,
+// languages are languages of translation selectable by lang parameter,
+// the default first.
+func Search(s search.Service, defaults search.Options, languages ...string) Service {
+	handler := &searchHandler{s, defaults, languages}
 	return func(r *mux.Router) {
 		r.NewRoute().
 			Methods("GET").
@@ -21,28 +29,235 @@ func Search(s search.Service) Service {
 
 type searchHandler struct {
 	search.Service
+	defaults  search.Options
+	languages []string
 }
 
 func (h *searchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var vowel, verbose bool
+	var verbose bool
 	r.ParseForm()
-	if _, ok := r.Form["vowel"]; ok {
-		vowel = true
-	}
 	if _, ok := r.Form["debug"]; ok {
 		verbose = true
 	}
+	opts, err := parseOptions(r, h.defaults, h.languages)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	query := []byte(r.FormValue("q"))
-	res := h.Search(query, vowel)
+	res, err := h.SearchContext(r.Context(), query, opts)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// client has gone, nobody to respond to
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("search %q: %v", query, err)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		log.Printf("search %q: %v", query, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 	t.ServeHTMLTemplate(w, r, t.Search, struct {
 		search.Result
-		Vowel, Verbose bool
+		Options   search.Options
+		Order     string
+		Scope     map[string]string
+		Languages []string
+		Page      page
+		Verbose   bool
 		t.CopyrightDate
 	}{
-		Result:        res,
-		Vowel:         vowel,
+		Result:  res,
+		Options: opts,
+		Order:   r.FormValue("order"),
+		Scope: map[string]string{
+			"Surah": scopeValue(r, "surah"),
+			"Juz":   scopeValue(r, "juz"),
+			"Hizb":  scopeValue(r, "hizb"),
+		},
+		Languages:     h.languages,
+		Page:          newPage(r, opts, res.FoundDoc),
 		Verbose:       verbose,
 		CopyrightDate: t.NewCopyrightDate(),
 	})
 }
+
+// pageSize is the number of documents per page if limit is not set.
+const pageSize = 20
+
+// maxRerank is the maximum rerank, local alignment of a document is
+// costly.
+const maxRerank = 2 * pageSize
+
+// maxVerses is the maximum verses, documents joined from verses grow with
+// it.
+const maxVerses = 5
+
+// page links to previous and next page of search result, link is empty
+// if there is no such page.
+type page struct {
+	Offset     int
+	Prev, Next string
+}
+
+func newPage(r *http.Request, opts search.Options, foundDoc int) page {
+	p := page{Offset: opts.Offset}
+	if opts.Offset > 0 && opts.Limit > 0 {
+		prev := opts.Offset - opts.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		p.Prev = pageURL(r, prev)
+	}
+	if opts.Limit > 0 && opts.Offset+opts.Limit < foundDoc {
+		p.Next = pageURL(r, opts.Offset+opts.Limit)
+	}
+	return p
+}
+
+// pageURL returns URL of r with offset replaced.
+func pageURL(r *http.Request, offset int) string {
+	u := *r.URL
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
+
+// parseOptions parses search options overriding defaults from form of r:
+//
+//	vowel      present to search with vowel
+//	order      "score" (default), "count" to order by matched tokens count,
+//	           "tfidf" or "bm25" to order by weighted trigrams
+//	nofilter   present to show documents under threshold
+//	idf        present to weight trigrams by inverse document frequency
+//	threshold  filter threshold in range [0, 1]
+//	limit      maximum number of documents, default 20, 0 for all
+//	offset     number of documents to skip
+//	rerank     number of best documents reranked by local alignment, at
+//	           most maxRerank
+//	verses     maximum number of adjacent verses searched as continuous
+//	           text, at most maxVerses
+//	surah      chapters to search such as 2, 1-3 or 2:1-5, comma separated
+//	juz        juz to search such as 30 or 29-30, comma separated
+//	hizb       hizb to search such as 60 or 59-60, comma separated
+//	lang       language of translation, one of languages
+func parseOptions(r *http.Request, defaults search.Options, languages []string) (search.Options, error) {
+	opts := defaults
+	if _, ok := r.Form["vowel"]; ok {
+		opts.Vowel = true
+	}
+	if _, ok := r.Form["nofilter"]; ok {
+		opts.Filter = false
+	}
+	if _, ok := r.Form["idf"]; ok {
+		opts.IDF = true
+	}
+	switch order := r.FormValue("order"); order {
+	case "", "score":
+	case "count":
+		opts.ScoreOrder = false
+	default:
+		scorer, err := search.NewScorer(order)
+		if err != nil {
+			return opts, fmt.Errorf("invalid order %q", order)
+		}
+		opts.Scorer = scorer
+	}
+	if lang := r.FormValue("lang"); lang != "" {
+		if !contains(languages, lang) {
+			return opts, fmt.Errorf("invalid lang %q", lang)
+		}
+		opts.Language = lang
+	}
+
+	if th := r.FormValue("threshold"); th != "" {
+		f, err := strconv.ParseFloat(th, 64)
+		if err != nil || f < 0 || f > 1 {
+			return opts, fmt.Errorf("invalid threshold %q", th)
+		}
+		opts.FilterThreshold = f
+	}
+
+	var err error
+	opts.Limit = pageSize
+	if _, ok := r.Form["limit"]; ok {
+		if opts.Limit, err = parseNonNegative(r, "limit"); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Offset, err = parseNonNegative(r, "offset"); err != nil {
+		return opts, err
+	}
+	if opts.Rerank, err = parseAtMost(r, "rerank", maxRerank); err != nil {
+		return opts, err
+	}
+	if opts.Verses, err = parseAtMost(r, "verses", maxVerses); err != nil {
+		return opts, err
+	}
+	for _, key := range scopeKeys {
+		for _, v := range strings.Split(scopeValue(r, key), ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			scope, err := search.ParseScope(scopeDivision[key], v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q", key, v)
+			}
+			opts.Scope = append(opts.Scope, scope)
+		}
+	}
+
+	return opts, nil
+}
+
+// scopeKeys are form keys of scope, parsed in order.
+var scopeKeys = []string{"surah", "juz", "hizb"}
+
+var scopeDivision = map[string]search.Division{
+	"surah": search.Chapter,
+	"juz":   search.Juz,
+	"hizb":  search.Hizb,
+}
+
+// scopeValue returns every value of key in form of r joined by comma.
+func scopeValue(r *http.Request, key string) string {
+	return strings.Join(r.Form[key], ",")
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAtMost is like parseNonNegative but value must be at most max.
+func parseAtMost(r *http.Request, key string, max int) (int, error) {
+	i, err := parseNonNegative(r, key)
+	if err != nil {
+		return 0, err
+	}
+	if i > max {
+		return 0, fmt.Errorf("invalid %s %q, at most %d", key, r.FormValue(key), max)
+	}
+	return i, nil
+}
+
+func parseNonNegative(r *http.Request, key string) (int, error) {
+	v := r.FormValue(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, v)
+	}
+	return i, nil
+}
@@ -14,7 +14,7 @@ type Service func(r *mux.Router)
 func NewServer(addr string, services ...Service) *http.Server {
 	r := mux.NewRouter()
 
-	services = append(services, asset, index, about)
+	services = append(services, index, about)
 	for _, service := range services {
 		service(r)
 	}
@@ -0,0 +1,155 @@
+// Package memory implements lafzi.Index held entirely in memory.
+package memory
+
+import (
+	"context"
+	"io"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/pkg/index"
+	"github.com/billyzaelani/go-lafzi/pkg/indexer"
+)
+
+// Document is a phonetic encoded document to be indexed.
+type Document struct {
+	ID       lafzi.ID
+	Phonetic []byte
+}
+
+// Index is an inverted index held in memory. Index is safe for
+// concurrent use, documents returned by Search must not be modified.
+type Index struct {
+	termsV, termsN *terms
+}
+
+// terms are postings of one encoding mode along with length of documents.
+// count includes documents too short to have a trigram.
+type terms struct {
+	docs    map[string][]lafzi.Document
+	lengths map[lafzi.ID]int
+	avg     float64
+	count   int
+}
+
+// NewIndex builds index from phonetic documents encoded with vowel docsV
+// and without vowel docsN.
+func NewIndex(docsV, docsN []Document) *Index {
+	return &Index{
+		termsV: build(docsV),
+		termsN: build(docsN),
+	}
+}
+
+func build(docs []Document) *terms {
+	b := indexer.NewBuilder()
+	for _, doc := range docs {
+		b.Add(doc.ID, doc.Phonetic)
+	}
+	return fromTerms(len(docs), b.Terms())
+}
+
+// Load loads index from snapshots in the format of package pkg/index,
+// such as the one written by Save or cmd/generateindex.
+func Load(indexV, indexN io.Reader) (*Index, error) {
+	tv, err := load(indexV)
+	if err != nil {
+		return nil, err
+	}
+	tn, err := load(indexN)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{
+		termsV: tv,
+		termsN: tn,
+	}, nil
+}
+
+func load(r io.Reader) (*terms, error) {
+	docCount, terms, err := index.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return fromTerms(docCount, terms), nil
+}
+
+func fromTerms(docCount int, ts []index.Term) *terms {
+	docs := make(map[string][]lafzi.Document, len(ts))
+	for _, term := range ts {
+		d := make([]lafzi.Document, len(term.Postings))
+		for i, p := range term.Postings {
+			d[i] = lafzi.Document{ID: p.ID, Term: p.Positions}
+		}
+		docs[term.Token] = d
+	}
+
+	t := &terms{docs: docs, lengths: index.Lengths(ts), count: docCount}
+	if len(t.lengths) > 0 {
+		var sum int
+		for _, l := range t.lengths {
+			sum += l
+		}
+		t.avg = float64(sum) / float64(len(t.lengths))
+	}
+	return t
+}
+
+// Save writes snapshots of index with vowel to indexV and without vowel
+// to indexN.
+func (idx *Index) Save(indexV, indexN io.Writer) error {
+	err := save(indexV, idx.termsV)
+	if err != nil {
+		return err
+	}
+	return save(indexN, idx.termsN)
+}
+
+func save(w io.Writer, t *terms) error {
+	ts := make([]index.Term, 0, len(t.docs))
+	for token, d := range t.docs {
+		postings := make([]index.Posting, len(d))
+		for i, doc := range d {
+			postings[i] = index.Posting{ID: doc.ID, Positions: doc.Term}
+		}
+		ts = append(ts, index.Term{Token: token, Postings: postings})
+	}
+	index.Sort(ts)
+
+	return index.Write(w, t.count, ts)
+}
+
+// Search ...
+func (idx *Index) Search(ctx context.Context, term string, v bool) ([]lafzi.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return idx.terms(v).docs[term], nil
+}
+
+// DocumentCount ...
+func (idx *Index) DocumentCount(v bool) int {
+	return idx.terms(v).count
+}
+
+// DocumentFrequency ...
+func (idx *Index) DocumentFrequency(term string, v bool) int {
+	return len(idx.terms(v).docs[term])
+}
+
+// DocumentLength ...
+func (idx *Index) DocumentLength(id lafzi.ID, v bool) int {
+	return idx.terms(v).lengths[id]
+}
+
+// AverageLength ...
+func (idx *Index) AverageLength(v bool) float64 {
+	return idx.terms(v).avg
+}
+
+func (idx *Index) terms(v bool) *terms {
+	if v {
+		return idx.termsV
+	}
+	return idx.termsN
+}
@@ -0,0 +1,127 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/memory"
+)
+
+var (
+	// Al-Fatihah(1) verse: 1-3
+	docsV = []memory.Document{
+		{1, []byte("BISMILAHIRAHMANIRAHIM")},
+		{2, []byte("XALHAMDULILAHIRABILXALAMIN")},
+		{3, []byte("XARAHMANIRAHIM")},
+	}
+	docsN = []memory.Document{
+		{1, []byte("BSMLHRHMNRHM")},
+		{2, []byte("XLHMDLLHRBLXLMN")},
+		{3, []byte("XRHMNRHM")},
+	}
+)
+
+func TestSearch(t *testing.T) {
+	idx := memory.NewIndex(docsV, docsN)
+	tables := []struct {
+		term     string
+		vowel    bool
+		expected []lafzi.Document
+	}{
+		{"RHM", false, []lafzi.Document{{ID: 1, Term: []int{6, 10}}, {ID: 3, Term: []int{2, 6}}}},
+		{"LAH", true, []lafzi.Document{{ID: 1, Term: []int{6}}, {ID: 2, Term: []int{11}}}},
+		{"XXX", true, nil},
+	}
+
+	for _, table := range tables {
+		actual, err := idx.Search(context.Background(), table.term, table.vowel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(table.expected, actual) {
+			t.Errorf("term: %s error, expected: %v, actual: %v", table.term, table.expected, actual)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	idx := memory.NewIndex(docsV, docsN)
+	var bufV, bufN bytes.Buffer
+	if err := idx.Save(&bufV, &bufN); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := memory.Load(&bufV, &bufN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, term := range []string{"BIS", "RAH", "MIN"} {
+		expected, _ := idx.Search(context.Background(), term, true)
+		actual, _ := loaded.Search(context.Background(), term, true)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("term: %s error, expected: %v, actual: %v", term, expected, actual)
+		}
+	}
+
+	// document without trigram is still counted
+	short := append(docsN, memory.Document{ID: 4, Phonetic: []byte("XL")})
+	idx = memory.NewIndex(short, short)
+	bufV.Reset()
+	bufN.Reset()
+	if err := idx.Save(&bufV, &bufN); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = memory.Load(&bufV, &bufN); err != nil {
+		t.Fatal(err)
+	}
+	for _, vowel := range []bool{true, false} {
+		if actual := loaded.DocumentCount(vowel); actual != len(short) {
+			t.Errorf("expected: %d, actual: %d", len(short), actual)
+		}
+	}
+}
+
+func TestSearchCanceled(t *testing.T) {
+	idx := memory.NewIndex(docsV, docsN)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := idx.Search(ctx, "RHM", false)
+	if err != context.Canceled {
+		t.Errorf("expected: %v, actual: %v", context.Canceled, err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	idx := memory.NewIndex(docsV, docsN)
+	tables := []struct {
+		vowel   bool
+		id      int
+		count   int
+		term    string
+		df      int
+		length  int
+		average float64
+	}{
+		// number of trigrams is phonetic code length minus 2
+		{false, 1, 3, "RHM", 2, 10, 29.0 / 3},
+		{true, 3, 3, "XXX", 0, 12, 55.0 / 3},
+	}
+
+	for _, table := range tables {
+		if actual := idx.DocumentCount(table.vowel); actual != table.count {
+			t.Errorf("expected: %d, actual: %d", table.count, actual)
+		}
+		if actual := idx.DocumentFrequency(table.term, table.vowel); actual != table.df {
+			t.Errorf("term: %s error, expected: %d, actual: %d", table.term, table.df, actual)
+		}
+		if actual := idx.DocumentLength(table.id, table.vowel); actual != table.length {
+			t.Errorf("expected: %d, actual: %d", table.length, actual)
+		}
+		if actual := idx.AverageLength(table.vowel); actual != table.average {
+			t.Errorf("expected: %v, actual: %v", table.average, actual)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package align provides local alignment of phonetic codes, tolerating
+// symbols commonly confused when transliterating.
+package align
+
+import "bytes"
+
+// Span is a half-open range [Start, End) of rune indices.
+type Span struct {
+	Start, End int
+}
+
+// Result is the best local alignment of query and text.
+type Result struct {
+	Score float64
+	// Query and Text are aligned spans of query and text.
+	Query, Text Span
+}
+
+// Scoring scores aligned pairs of symbols, Mismatch and Gap are expected
+// to be negative.
+type Scoring struct {
+	Match, Mismatch, Gap float64
+	// Similar scores substitution of commonly confused symbols in either
+	// order, it overrides Mismatch.
+	Similar map[[2]rune]float64
+}
+
+// DefaultScoring returns scoring of lafzi phonetic code where similar
+// sounding symbols score half of a match.
+func DefaultScoring() Scoring {
+	return Scoring{
+		Match:    1,
+		Mismatch: -1,
+		Gap:      -1,
+		Similar: map[[2]rune]float64{
+			{'S', 'Z'}: 0.5, // sa, tsa, sya and za, dza
+			{'H', 'X'}: 0.5, // ha and 'ain
+			{'D', 'Z'}: 0.5, // dal and dzal
+			{'D', 'T'}: 0.5, // dal and ta
+			{'K', 'G'}: 0.5, // qaf and ghain
+			{'G', 'X'}: 0.5, // ghain and 'ain
+			{'F', 'B'}: 0.5, // fa and p
+			{'N', 'M'}: 0.5, // iqlab
+			{'A', 'I'}: 0.5,
+			{'A', 'U'}: 0.5,
+			{'I', 'U'}: 0.5,
+			{'I', 'Y'}: 0.5,
+			{'U', 'W'}: 0.5,
+		},
+	}
+}
+
+// Substitution returns score of aligning a to b.
+func (s Scoring) Substitution(a, b rune) float64 {
+	if a == b {
+		return s.Match
+	}
+	if score, ok := s.Similar[[2]rune{a, b}]; ok {
+		return score
+	}
+	if score, ok := s.Similar[[2]rune{b, a}]; ok {
+		return score
+	}
+	return s.Mismatch
+}
+
+// MaxScore returns score of query aligned to itself.
+func (s Scoring) MaxScore(query []byte) float64 {
+	return s.Match * float64(len(bytes.Runes(query)))
+}
+
+// Local returns the best local alignment of query in text using
+// Smith-Waterman algorithm with linear gap. On equal score the alignment
+// ending first in text is returned. Zero Result is returned if nothing
+// aligns.
+func Local(query, text []byte, s Scoring) Result {
+	q, t := bytes.Runes(query), bytes.Runes(text)
+
+	// rows of scores and where their alignment starts
+	type cell struct {
+		score          float64
+		qStart, tStart int
+	}
+	prev := make([]cell, len(t)+1)
+	curr := make([]cell, len(t)+1)
+
+	var best Result
+	for i := 1; i <= len(q); i++ {
+		curr[0] = cell{}
+		for j := 1; j <= len(t); j++ {
+			c := cell{qStart: i - 1, tStart: j - 1}
+			if d := prev[j-1]; d.score > 0 {
+				c.qStart, c.tStart = d.qStart, d.tStart
+			}
+			c.score = prev[j-1].score + s.Substitution(q[i-1], t[j-1])
+			if up := prev[j]; up.score+s.Gap > c.score {
+				c = cell{up.score + s.Gap, up.qStart, up.tStart}
+			}
+			if left := curr[j-1]; left.score+s.Gap > c.score {
+				c = cell{left.score + s.Gap, left.qStart, left.tStart}
+			}
+			if c.score <= 0 {
+				c = cell{}
+			}
+			curr[j] = c
+			if c.score > best.Score || c.score == best.Score && c.score > 0 && j < best.Text.End {
+				best = Result{
+					Score: c.score,
+					Query: Span{c.qStart, i},
+					Text:  Span{c.tStart, j},
+				}
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return best
+}
@@ -0,0 +1,52 @@
+package align_test
+
+import (
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/pkg/align"
+)
+
+func TestLocal(t *testing.T) {
+	s := align.DefaultScoring()
+	tables := []struct {
+		query, text string
+		expected    align.Result
+	}{
+		{"RHMN", "BSMLHRHMNRHM", align.Result{4, align.Span{0, 4}, align.Span{5, 9}}},
+		// wrong letter in the middle
+		{"XLHMTLLH", "XLHMDLLHRBLXLMN", align.Result{7.5, align.Span{0, 8}, align.Span{0, 8}}},
+		{"XLHMKLLH", "XLHMDLLHRBLXLMN", align.Result{6, align.Span{0, 8}, align.Span{0, 8}}},
+		// missing letter
+		{"BSMLRHMN", "BSMLHRHMNRHM", align.Result{7, align.Span{0, 8}, align.Span{0, 9}}},
+		// on equal score the first is returned
+		{"RHM", "BSMLHRHMNRHM", align.Result{3, align.Span{0, 3}, align.Span{5, 8}}},
+		{"QQQ", "BSMLH", align.Result{}},
+		{"", "BSMLH", align.Result{}},
+	}
+
+	for _, table := range tables {
+		actual := align.Local([]byte(table.query), []byte(table.text), s)
+		if actual != table.expected {
+			t.Errorf("query: %s error, expected: %+v, actual: %+v", table.query, table.expected, actual)
+		}
+	}
+}
+
+func TestSubstitution(t *testing.T) {
+	s := align.DefaultScoring()
+	tables := []struct {
+		a, b     rune
+		expected float64
+	}{
+		{'S', 'S', 1},
+		{'S', 'Z', 0.5},
+		{'Z', 'S', 0.5},
+		{'S', 'M', -1},
+	}
+
+	for _, table := range tables {
+		if actual := s.Substitution(table.a, table.b); actual != table.expected {
+			t.Errorf("pair: %c%c error, expected: %v, actual: %v", table.a, table.b, table.expected, actual)
+		}
+	}
+}
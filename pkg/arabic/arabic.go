@@ -82,3 +82,13 @@ var (
 	RHFCStop       = '\u06ec' // http://www.fileformat.info/info/unicode/char/06ec/index.htm
 	SLMeem         = '\u06ed' // http://www.fileformat.info/info/unicode/char/06ed/index.htm
 )
+
+// Variant characters of simple script and other keyboard layouts. Prefix:
+// W = wavy.
+var (
+	HamzaB      = '\u0655' // http://www.fileformat.info/info/unicode/char/0655/index.htm
+	AlefWHamzaA = '\u0672' // http://www.fileformat.info/info/unicode/char/0672/index.htm
+	AlefWHamzaB = '\u0673' // http://www.fileformat.info/info/unicode/char/0673/index.htm
+	Keheh       = '\u06a9' // http://www.fileformat.info/info/unicode/char/06a9/index.htm
+	FarsiYeh    = '\u06cc' // http://www.fileformat.info/info/unicode/char/06cc/index.htm
+)
@@ -0,0 +1,376 @@
+// Package index implements the binary on-disk format of the inverted
+// index.
+//
+// An index file consists of a fixed-size header, a term dictionary
+// sorted by token and a postings section:
+//
+//	header (little endian)
+//		magic       [4]byte "LFZI"
+//		version     uint16
+//		reserved    uint16
+//		documents   uint32 number of indexed documents
+//		terms       uint32 number of terms in dictionary
+//		dictionary  uint64 size of dictionary section in bytes
+//
+//	dictionary, for every term in ascending order
+//		uvarint     length of token
+//		[]byte      token
+//		uvarint     number of postings (document frequency)
+//		uvarint     size of posting block in bytes
+//
+//	postings, for every term in the same order as dictionary
+//		uvarint     delta of document ID from the previous posting
+//		uvarint     number of positions
+//		uvarint...  delta of position from the previous position
+//
+// The offset of a posting block is the sum of the sizes of the blocks
+// before it, so it is never stored.
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+)
+
+// Magic is the first four bytes of every index file.
+const Magic = "LFZI"
+
+// Version is the version of the format written by Write.
+const Version = 1
+
+const headerSize = 24
+
+// Errors returned when reading malformed index.
+var (
+	ErrMagic   = errors.New("index: not an index file")
+	ErrVersion = errors.New("index: unsupported version")
+	ErrFormat  = errors.New("index: malformed index")
+)
+
+// Posting is an occurence of a term in a document.
+type Posting struct {
+	ID        int
+	Positions []int
+}
+
+// Term is a token with its postings list sorted by document ID.
+type Term struct {
+	Token    string
+	Postings []Posting
+}
+
+// Write writes docCount and terms to w. Terms must be sorted by token,
+// postings must be sorted by ID and positions must be ascending.
+func Write(w io.Writer, docCount int, terms []Term) error {
+	var dict, postings bytes.Buffer
+	var block []byte
+	for i, term := range terms {
+		if i > 0 && terms[i-1].Token >= term.Token {
+			return fmt.Errorf("index: terms not sorted at %q", term.Token)
+		}
+		block = block[:0]
+		var prevID int
+		for _, p := range term.Postings {
+			if p.ID < prevID {
+				return fmt.Errorf("index: postings of %q not sorted", term.Token)
+			}
+			block = appendUvarint(block, p.ID-prevID)
+			block = appendUvarint(block, len(p.Positions))
+			var prevPos int
+			for _, pos := range p.Positions {
+				if pos < prevPos {
+					return fmt.Errorf("index: positions of %q not sorted", term.Token)
+				}
+				block = appendUvarint(block, pos-prevPos)
+				prevPos = pos
+			}
+			prevID = p.ID
+		}
+		postings.Write(block)
+
+		var entry []byte
+		entry = appendUvarint(entry, len(term.Token))
+		entry = append(entry, term.Token...)
+		entry = appendUvarint(entry, len(term.Postings))
+		entry = appendUvarint(entry, len(block))
+		dict.Write(entry)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, Magic)
+	binary.LittleEndian.PutUint16(header[4:], Version)
+	binary.LittleEndian.PutUint32(header[8:], uint32(docCount))
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(terms)))
+	binary.LittleEndian.PutUint64(header[16:], uint64(dict.Len()))
+
+	for _, b := range [][]byte{header, dict.Bytes(), postings.Bytes()} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendUvarint(b []byte, x int) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(x))
+	return append(b, buf[:n]...)
+}
+
+// Reader reads postings from an index. The dictionary is kept in memory,
+// postings are read from the underlying io.ReaderAt on demand.
+// Reader is safe for concurrent use if the underlying io.ReaderAt is.
+type Reader struct {
+	r        io.ReaderAt
+	docCount int
+	tokens   []string
+	dict     map[string]entry
+}
+
+type entry struct {
+	offset, size int64
+	df           int
+}
+
+// NewReader reads header and dictionary of the index from r.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			return nil, ErrMagic
+		}
+		return nil, err
+	}
+	docCount, termCount, dictSize, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	// every entry takes at least 3 bytes
+	if dictSize < 0 || int64(termCount)*3 > dictSize {
+		return nil, ErrFormat
+	}
+
+	br := &limitedReader{bufio.NewReader(io.NewSectionReader(r, headerSize, dictSize)), dictSize}
+	rd := &Reader{
+		r:        r,
+		docCount: docCount,
+		tokens:   make([]string, 0, termCount),
+		dict:     make(map[string]entry, termCount),
+	}
+	offset := int64(headerSize) + dictSize
+	for i := 0; i < termCount; i++ {
+		token, df, size, err := readEntry(br)
+		if err != nil {
+			return nil, err
+		}
+		rd.tokens = append(rd.tokens, token)
+		rd.dict[token] = entry{offset: offset, size: size, df: df}
+		offset += size
+	}
+
+	return rd, nil
+}
+
+func parseHeader(header []byte) (docCount, termCount int, dictSize int64, err error) {
+	if string(header[:4]) != Magic {
+		return 0, 0, 0, ErrMagic
+	}
+	if binary.LittleEndian.Uint16(header[4:]) != Version {
+		return 0, 0, 0, ErrVersion
+	}
+	docCount = int(binary.LittleEndian.Uint32(header[8:]))
+	termCount = int(binary.LittleEndian.Uint32(header[12:]))
+	dictSize = int64(binary.LittleEndian.Uint64(header[16:]))
+
+	return docCount, termCount, dictSize, nil
+}
+
+// limitedReader reads at most n bytes.
+type limitedReader struct {
+	br io.ByteReader
+	n  int64
+}
+
+func (l *limitedReader) ReadByte() (byte, error) {
+	if l.n <= 0 {
+		return 0, io.EOF
+	}
+	l.n--
+	return l.br.ReadByte()
+}
+
+func readEntry(br *limitedReader) (token string, df int, size int64, err error) {
+	n, err := readUvarint(br)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if int64(n) > br.n {
+		return "", 0, 0, ErrFormat
+	}
+	b := make([]byte, n)
+	for i := range b {
+		if b[i], err = br.ReadByte(); err != nil {
+			return "", 0, 0, ErrFormat
+		}
+	}
+	if df, err = readUvarint(br); err != nil {
+		return "", 0, 0, err
+	}
+	s, err := readUvarint(br)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	// every posting takes at least 2 bytes
+	if df > s/2 {
+		return "", 0, 0, ErrFormat
+	}
+
+	return string(b), df, int64(s), nil
+}
+
+func readUvarint(br io.ByteReader) (int, error) {
+	x, err := binary.ReadUvarint(br)
+	if err != nil || x > math.MaxInt32 {
+		return 0, ErrFormat
+	}
+	return int(x), nil
+}
+
+// ReadAll reads the whole index from r.
+func ReadAll(r io.Reader) (docCount int, terms []Term, err error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	rd, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		return 0, nil, err
+	}
+	terms = make([]Term, len(rd.tokens))
+	for i, token := range rd.tokens {
+		postings, err := rd.Postings(token)
+		if err != nil {
+			return 0, nil, err
+		}
+		terms[i] = Term{Token: token, Postings: postings}
+	}
+
+	return rd.docCount, terms, nil
+}
+
+// DocumentCount returns the number of documents in the index.
+func (rd *Reader) DocumentCount() int {
+	return rd.docCount
+}
+
+// Tokens returns all tokens in the index in ascending order.
+func (rd *Reader) Tokens() []string {
+	return rd.tokens
+}
+
+// DocumentFrequency returns the number of documents containing token.
+func (rd *Reader) DocumentFrequency(token string) int {
+	return rd.dict[token].df
+}
+
+// Lengths reads all postings and returns the number of positions, that
+// is the number of trigrams, of every document.
+func (rd *Reader) Lengths() (map[int]int, error) {
+	lengths := make(map[int]int, rd.docCount)
+	for _, token := range rd.tokens {
+		postings, err := rd.Postings(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range postings {
+			lengths[p.ID] += len(p.Positions)
+		}
+	}
+	return lengths, nil
+}
+
+// Lengths returns the number of positions of every document in terms.
+func Lengths(terms []Term) map[int]int {
+	lengths := make(map[int]int)
+	for _, t := range terms {
+		for _, p := range t.Postings {
+			lengths[p.ID] += len(p.Positions)
+		}
+	}
+	return lengths
+}
+
+// Postings returns postings of token. It returns nil without error if
+// token is not in the index.
+func (rd *Reader) Postings(token string) ([]Posting, error) {
+	e, ok := rd.dict[token]
+	if !ok {
+		return nil, nil
+	}
+	if e.size == 0 {
+		return []Posting{}, nil
+	}
+	// size is not trusted to allocate, index may be truncated
+	block, err := ioutil.ReadAll(io.NewSectionReader(rd.r, e.offset, e.size))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(block)) != e.size {
+		return nil, ErrFormat
+	}
+
+	return decodePostings(block, e.df)
+}
+
+func decodePostings(block []byte, df int) ([]Posting, error) {
+	br := bytes.NewReader(block)
+	postings := make([]Posting, df)
+	var id int
+	for i := range postings {
+		delta, err := readUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		n, err := readUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if n > br.Len() {
+			return nil, ErrFormat
+		}
+		id += delta
+		positions := make([]int, n)
+		var pos int
+		for j := range positions {
+			delta, err := readUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			pos += delta
+			positions[j] = pos
+		}
+		postings[i] = Posting{ID: id, Positions: positions}
+	}
+
+	return postings, nil
+}
+
+// Sort sorts terms by token and their postings by ID, as required by
+// Write.
+func Sort(terms []Term) {
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].Token < terms[j].Token
+	})
+	for _, t := range terms {
+		sort.Slice(t.Postings, func(i, j int) bool {
+			return t.Postings[i].ID < t.Postings[j].ID
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package index_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/pkg/index"
+)
+
+func TestWriteRead(t *testing.T) {
+	terms := []index.Term{
+		{"BSM", []index.Posting{{1, []int{1}}}},
+		{"LLH", []index.Posting{{1, []int{4}}, {2, []int{3, 130}}, {300, []int{1}}}},
+		{"SML", []index.Posting{{1, []int{2}}}},
+	}
+	var buf bytes.Buffer
+	if err := index.Write(&buf, 300, terms); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := index.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.DocumentCount() != 300 {
+		t.Errorf("expected: %d, actual: %d", 300, r.DocumentCount())
+	}
+	if len(r.Tokens()) != len(terms) {
+		t.Errorf("expected: %d, actual: %d", len(terms), len(r.Tokens()))
+	}
+	for _, term := range terms {
+		actual, err := r.Postings(term.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(term.Postings, actual) {
+			t.Errorf("token: %s error, expected: %v, actual: %v", term.Token, term.Postings, actual)
+		}
+		if r.DocumentFrequency(term.Token) != len(term.Postings) {
+			t.Errorf("token: %s error, expected df: %d, actual df: %d",
+				term.Token, len(term.Postings), r.DocumentFrequency(term.Token))
+		}
+	}
+
+	actual, err := r.Postings("XXX")
+	if actual != nil || err != nil {
+		t.Errorf("expected: nil, actual: %v, %v", actual, err)
+	}
+
+	expectedLengths := map[int]int{1: 3, 2: 2, 300: 1}
+	lengths, err := r.Lengths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedLengths, lengths) {
+		t.Errorf("expected: %v, actual: %v", expectedLengths, lengths)
+	}
+	if lengths := index.Lengths(terms); !reflect.DeepEqual(expectedLengths, lengths) {
+		t.Errorf("expected: %v, actual: %v", expectedLengths, lengths)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	tables := []struct {
+		b        []byte
+		expected error
+	}{
+		{[]byte("LFZ"), index.ErrMagic},
+		{[]byte("TERM|0\nTERN|10\n------------"), index.ErrMagic},
+		{append([]byte("LFZI\x09\x00"), make([]byte, 18)...), index.ErrVersion},
+	}
+
+	for _, table := range tables {
+		_, err := index.NewReader(bytes.NewReader(table.b))
+		if err != table.expected {
+			t.Errorf("expected: %v, actual: %v", table.expected, err)
+		}
+	}
+}
+
+func TestCorrupted(t *testing.T) {
+	var buf bytes.Buffer
+	terms := []index.Term{{"BSM", []index.Posting{{1, []int{1}}}}}
+	if err := index.Write(&buf, 1, terms); err != nil {
+		t.Fatal(err)
+	}
+	// header is 24 bytes, followed by entry of token length, token BSM,
+	// df and size of postings block
+	tables := []struct {
+		offset int
+		b      byte
+	}{
+		{15, 0x7f}, // term count
+		{23, 0x7f}, // dictionary size
+		{24, 0x7f}, // token length
+		{28, 0x7f}, // df
+		{29, 0x7f}, // postings block size
+		{31, 0x7f}, // number of positions
+	}
+
+	for _, table := range tables {
+		b := append([]byte(nil), buf.Bytes()...)
+		b[table.offset] = table.b
+		_, _, err := index.ReadAll(bytes.NewReader(b))
+		if err != index.ErrFormat {
+			t.Errorf("offset: %d error, expected: %v, actual: %v", table.offset, index.ErrFormat, err)
+		}
+	}
+}
+
+func TestWriteUnsorted(t *testing.T) {
+	terms := []index.Term{
+		{"SML", []index.Posting{{1, []int{2}}}},
+		{"BSM", []index.Posting{{1, []int{1}}}},
+	}
+	if err := index.Write(&bytes.Buffer{}, 1, terms); err == nil {
+		t.Error("expected error on unsorted terms")
+	}
+}
@@ -0,0 +1,106 @@
+// Package indexer builds inverted index of phonetic documents in the
+// format of package pkg/index.
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/billyzaelani/go-lafzi/pkg/index"
+	"github.com/billyzaelani/go-lafzi/pkg/trigram"
+)
+
+// Builder accumulates postings of phonetic documents. The zero value is
+// ready to use.
+type Builder struct {
+	postings map[string][]index.Posting
+	ids      map[int]struct{}
+}
+
+// NewBuilder returns a new Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Add extracts trigram from phonetic and adds it as document id.
+// Documents may be added in any order.
+func (b *Builder) Add(id int, phonetic []byte) {
+	if b.postings == nil {
+		b.postings = make(map[string][]index.Posting)
+		b.ids = make(map[int]struct{})
+	}
+	for _, token := range trigram.Extract(phonetic) {
+		b.postings[token.Token()] = append(b.postings[token.Token()], index.Posting{
+			ID:        id,
+			Positions: token.Position(),
+		})
+	}
+	b.ids[id] = struct{}{}
+}
+
+// AddCorpus adds every document of phonetic corpus r. Every line of
+// corpus has format "id|phonetic", as generated by cmd/generatecorpus.
+func (b *Builder) AddCorpus(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	var line int
+	for sc.Scan() {
+		line++
+		// split delim "|"
+		// [0] = id doc
+		// [1] = phonetic
+		data := bytes.SplitN(sc.Bytes(), []byte("|"), 2)
+		if len(data) != 2 {
+			return fmt.Errorf("indexer: line %d: missing separator", line)
+		}
+		id, err := strconv.Atoi(string(data[0]))
+		if err != nil {
+			return fmt.Errorf("indexer: line %d: %v", line, err)
+		}
+		b.Add(id, data[1])
+	}
+
+	return sc.Err()
+}
+
+// DocumentCount returns the number of added documents.
+func (b *Builder) DocumentCount() int {
+	return len(b.ids)
+}
+
+// TermCount returns the number of unique terms.
+func (b *Builder) TermCount() int {
+	return len(b.postings)
+}
+
+// Terms returns terms sorted by token with postings sorted by ID.
+func (b *Builder) Terms() []index.Term {
+	terms := make([]index.Term, 0, len(b.postings))
+	for token, postings := range b.postings {
+		terms = append(terms, index.Term{Token: token, Postings: postings})
+	}
+	index.Sort(terms)
+
+	return terms
+}
+
+// WriteTo writes the index to w.
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	cw := countWriter{w: w}
+	err := index.Write(&cw, b.DocumentCount(), b.Terms())
+
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
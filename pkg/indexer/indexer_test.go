@@ -0,0 +1,66 @@
+package indexer_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/pkg/index"
+	"github.com/billyzaelani/go-lafzi/pkg/indexer"
+)
+
+func TestBuilder(t *testing.T) {
+	var b indexer.Builder
+	// added out of order
+	b.Add(3, []byte("XRHMNRHM"))
+	b.Add(1, []byte("BSMLHRHMNRHM"))
+
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected: %d, actual: %d", buf.Len(), n)
+	}
+
+	r, err := index.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.DocumentCount() != 2 {
+		t.Errorf("expected: %d, actual: %d", 2, r.DocumentCount())
+	}
+	expected := []index.Posting{{ID: 1, Positions: []int{6, 10}}, {ID: 3, Positions: []int{2, 6}}}
+	actual, err := r.Postings("RHM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestAddCorpus(t *testing.T) {
+	tables := []struct {
+		corpus string
+		count  int
+		valid  bool
+	}{
+		{"1|BSMLHRHMNRHM\n2|XLHMDLLHRBLXLMN\n3|XRHMNRHM\n", 3, true},
+		{"1|BSMLHRHMNRHM\nXLHMDLLHRBLXLMN\n", 1, false},
+		{"X|BSMLHRHMNRHM\n", 0, false},
+	}
+
+	for _, table := range tables {
+		b := indexer.NewBuilder()
+		err := b.AddCorpus(strings.NewReader(table.corpus))
+		if (err == nil) != table.valid {
+			t.Errorf("corpus: %q error, expected valid: %t, actual: %v", table.corpus, table.valid, err)
+		}
+		if b.DocumentCount() != table.count {
+			t.Errorf("corpus: %q error, expected: %d, actual: %d", table.corpus, table.count, b.DocumentCount())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package overlay provides file system made of layers of file systems,
+// where file of an upper layer hides file of the same name below it.
+package overlay
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// FS is layers of file systems, the first is the upper most. Directories
+// are not merged, directory is read from the upper most layer having it.
+type FS []fs.FS
+
+// New returns file system of layers, nil layers are skipped.
+func New(layers ...fs.FS) FS {
+	var f FS
+	for _, layer := range layers {
+		if layer != nil {
+			f = append(f, layer)
+		}
+	}
+	return f
+}
+
+// Open opens name from the upper most layer having it.
+func (f FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range f {
+		file, err := layer.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
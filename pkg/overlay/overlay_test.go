@@ -0,0 +1,51 @@
+package overlay_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/billyzaelani/go-lafzi/pkg/overlay"
+)
+
+func TestFS(t *testing.T) {
+	upper := fstest.MapFS{
+		"a.txt":     {Data: []byte("upper a")},
+		"dir/b.txt": {Data: []byte("upper b")},
+	}
+	lower := fstest.MapFS{
+		"a.txt":     {Data: []byte("lower a")},
+		"c.txt":     {Data: []byte("lower c")},
+		"dir/d.txt": {Data: []byte("lower d")},
+	}
+	fsys := overlay.New(upper, nil, lower)
+
+	tables := []struct {
+		name     string
+		expected string
+	}{
+		{"a.txt", "upper a"},
+		{"c.txt", "lower c"},
+		{"dir/b.txt", "upper b"},
+		{"dir/d.txt", "lower d"},
+	}
+	for _, table := range tables {
+		b, err := fs.ReadFile(fsys, table.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := string(b); actual != table.expected {
+			t.Errorf("name: %s error, expected: %s, actual: %s", table.name, table.expected, actual)
+		}
+	}
+
+	for _, name := range []string{"e.txt", "../a.txt"} {
+		if _, err := fsys.Open(name); err == nil {
+			t.Errorf("name: %s error, expected: error, actual: %v", name, err)
+		}
+	}
+	if _, err := fsys.Open("e.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected: %v, actual: %v", fs.ErrNotExist, err)
+	}
+}
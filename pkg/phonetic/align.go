@@ -0,0 +1,205 @@
+package phonetic
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Range is a half-open range [Start, End) of rune indices in source text.
+type Range struct {
+	Start, End int
+}
+
+// Alignment maps every rune of an encoded text to the range of source
+// runes it came from. Runes inserted by encoding are mapped to an empty
+// range or to the range of their neighbour.
+type Alignment []Range
+
+// OffsetEncoder is implemented by encoders able to track which runes of
+// src produce each rune of phonetic code.
+type OffsetEncoder interface {
+	Encoder
+	// EncodeWithOffsets returns the same phonetic code as Encode and
+	// its alignment to src.
+	EncodeWithOffsets(src []byte, vowel bool) ([]byte, Alignment)
+}
+
+// Stage is a step of encoding pipeline.
+type Stage func(b []byte) []byte
+
+// Identity returns alignment of text of n runes to itself.
+func Identity(n int) Alignment {
+	a := make(Alignment, n)
+	for i := range a {
+		a[i] = Range{i, i + 1}
+	}
+	return a
+}
+
+// EncodeStages applies stages to src in order and tracks alignment of
+// the result to src by comparing input and output of every stage.
+func EncodeStages(src []byte, stages ...Stage) ([]byte, Alignment) {
+	b := src
+	a := Identity(utf8.RuneCount(src))
+	for _, stage := range stages {
+		next := stage(b)
+		a = a.Compose(Align(b, next))
+		b = next
+	}
+	return b, a
+}
+
+// Compose returns alignment of text c to source a, given alignment a of
+// intermediate text b to source a and alignment b of text c to b.
+func (a Alignment) Compose(b Alignment) Alignment {
+	c := make(Alignment, len(b))
+	for i, r := range b {
+		if r.Start >= r.End {
+			// empty range, keep it empty at the same place in source
+			var p int
+			switch {
+			case r.Start < len(a):
+				p = a[r.Start].Start
+			case len(a) > 0:
+				p = a[len(a)-1].End
+			}
+			c[i] = Range{p, p}
+			continue
+		}
+		c[i] = a[r.Start]
+		for _, x := range a[r.Start+1 : r.End] {
+			if x.Start < c[i].Start {
+				c[i].Start = x.Start
+			}
+			if x.End > c[i].End {
+				c[i].End = x.End
+			}
+		}
+	}
+	return c
+}
+
+// Bytes converts rune ranges of a to byte ranges of src.
+func (a Alignment) Bytes(src []byte) Alignment {
+	offsets := make([]int, 0, len(src)+1)
+	for i := range string(src) {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(src))
+
+	b := make(Alignment, len(a))
+	for i, r := range a {
+		b[i] = Range{offsets[r.Start], offsets[r.End]}
+	}
+	return b
+}
+
+// Align aligns runes of dst to runes of src, where dst is a rewrite of
+// src. Runes common to both, found by the shortest edit script, are
+// aligned one to one. Runes of dst between two common runes are aligned
+// to the runes of src between them, or to the next common rune if dst
+// only inserts there. Runes of src deleted by the rewrite are not
+// aligned to any rune of dst.
+func Align(src, dst []byte) Alignment {
+	s, d := bytes.Runes(src), bytes.Runes(dst)
+	a := make(Alignment, len(d))
+	var i, j int
+	for _, m := range matches(s, d) {
+		gap(a, j, m[1], i, m[0], len(s))
+		a[m[1]] = Range{m[0], m[0] + 1}
+		i, j = m[0]+1, m[1]+1
+	}
+	gap(a, j, len(d), i, len(s), len(s))
+
+	return a
+}
+
+// gap aligns unmatched dst[j0:j1] to unmatched src[i0:i1].
+func gap(a Alignment, j0, j1, i0, i1, n int) {
+	r := Range{i0, i1}
+	if i0 == i1 {
+		switch {
+		case i1 < n:
+			r = Range{i1, i1 + 1}
+		case i0 > 0:
+			r = Range{i0 - 1, i0}
+		}
+	}
+	for j := j0; j < j1; j++ {
+		a[j] = r
+	}
+}
+
+// matches returns pairs of indices of common runes of a and b using
+// Myers' diff algorithm.
+func matches(a, b []rune) [][2]int {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	var x, y int
+	var done bool
+	for d := 0; d <= max && !done; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y = x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x == n && y == m {
+				done = true
+				break
+			}
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+	}
+
+	// backtrack
+	var pairs [][2]int
+	x, y = n, m
+	for d := len(trace) - 1; d >= 0 && (x > 0 || y > 0); d-- {
+		k := x - y
+		var prevK int
+		if d == 0 {
+			for x > 0 && y > 0 {
+				x--
+				y--
+				pairs = append(pairs, [2]int{x, y})
+			}
+			break
+		}
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+
+	// reverse into ascending order
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	return pairs
+}
@@ -0,0 +1,56 @@
+package phonetic_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
+)
+
+func TestAlign(t *testing.T) {
+	tables := []struct {
+		src, dst string
+		expected phonetic.Alignment
+	}{
+		{"ABC", "ABC", phonetic.Alignment{{0, 1}, {1, 2}, {2, 3}}},
+		// deletion
+		{"ABBC", "ABC", phonetic.Alignment{{0, 1}, {1, 2}, {3, 4}}},
+		// substitution
+		{"ATHA", "ASA", phonetic.Alignment{{0, 1}, {1, 3}, {3, 4}}},
+		// insertion
+		{"AB", "XAB", phonetic.Alignment{{0, 1}, {0, 1}, {1, 2}}},
+		{"AB", "ABX", phonetic.Alignment{{0, 1}, {1, 2}, {1, 2}}},
+		{"", "", phonetic.Alignment{}},
+	}
+
+	for _, table := range tables {
+		actual := phonetic.Align([]byte(table.src), []byte(table.dst))
+		if !reflect.DeepEqual(actual, table.expected) {
+			t.Errorf("align %s to %s error, expected: %v, actual: %v", table.dst, table.src, table.expected, actual)
+		}
+	}
+}
+
+func TestEncodeStages(t *testing.T) {
+	join := func(b []byte) []byte { return bytes.Replace(b, []byte("LL"), []byte("L"), -1) }
+	th := func(b []byte) []byte { return bytes.Replace(b, []byte("TH"), []byte("S"), -1) }
+
+	code, a := phonetic.EncodeStages([]byte("ALLATH"), join, th)
+	if string(code) != "ALAS" {
+		t.Errorf("expected: %s, actual: %s", "ALAS", code)
+	}
+	expected := phonetic.Alignment{{0, 1}, {1, 2}, {3, 4}, {4, 6}}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, a)
+	}
+}
+
+func TestAlignmentBytes(t *testing.T) {
+	src := []byte("بِسْمِ")
+	a := phonetic.Alignment{{0, 2}, {2, 5}}
+	expected := phonetic.Alignment{{0, 4}, {4, 10}}
+	if actual := a.Bytes(src); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
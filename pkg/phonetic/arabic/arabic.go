@@ -6,11 +6,13 @@ package arabic
 
 import (
 	"bytes"
+	"fmt"
 	"regexp"
 	"unicode"
 	"unicode/utf8"
 
 	ar "github.com/billyzaelani/go-lafzi/pkg/arabic"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
 )
 
 // length arabic letter in bytes
@@ -26,45 +28,105 @@ const (
 // when start encoding.
 type LettersMode int
 
-// Encoder implements arabic-phonetic encoding.
+// String returns name of letters mode.
+func (m LettersMode) String() string {
+	switch m {
+	case LettersSimple:
+		return "simple"
+	case LettersUthmani:
+		return "uthmani"
+	}
+	return fmt.Sprintf("LettersMode(%d)", int(m))
+}
+
+// ParseLettersMode returns letters mode of name written by String.
+func ParseLettersMode(name string) (LettersMode, error) {
+	switch name {
+	case "simple":
+		return LettersSimple, nil
+	case "uthmani":
+		return LettersUthmani, nil
+	}
+	return 0, fmt.Errorf("arabic: unknown letters mode %q", name)
+}
+
+// Encoder implements arabic-phonetic encoding. The zero value uses
+// LettersSimple. Encoder is immutable and safe for concurrent use.
 type Encoder struct {
 	lettersMode LettersMode
-	harakat     bool
 }
 
-// SetLettersMode sets letters mode. Default letters mode is
-// LettersSimple.
-func (enc *Encoder) SetLettersMode(mode LettersMode) {
-	enc.lettersMode = mode
+// NewEncoder returns encoder for input stream with letters mode.
+func NewEncoder(mode LettersMode) *Encoder {
+	return &Encoder{lettersMode: mode}
 }
 
-// SetHarakat sets harakat. If set to true encoding will use harakat,
-// Otherwise harakat will be removed. Default is false.
-func (enc *Encoder) SetHarakat(harakat bool) {
-	enc.harakat = harakat
+// Encode returns encoded of src using encoding enc. If harakat is true
+// encoding will use harakat, otherwise harakat will be removed.
+func (enc *Encoder) Encode(src []byte, harakat bool) []byte {
+	b := src
+	for _, stage := range enc.stages(src, harakat) {
+		b = stage(b)
+	}
+
+	return Encode(b)
 }
 
-// Encode returns encoded of src using encoding enc.
-func (enc *Encoder) Encode(src []byte) []byte {
-	var b []byte
+// EncodeWithOffsets is like Encode but also returns alignment of every
+// phonetic code to the runes of src it came from.
+func (enc *Encoder) EncodeWithOffsets(src []byte, harakat bool) ([]byte, phonetic.Alignment) {
+	b, a := phonetic.EncodeStages(src, enc.stages(src, harakat)...)
+
+	// Encode maps letter to phonetic code one to one and drops the rest
+	buf := make([]byte, len(b))
+	code := make(phonetic.Alignment, 0, len(a))
+	n, i := 0, 0
+	for _, r := range string(b) {
+		if phon, ok := Mapping[r]; ok {
+			n += utf8.EncodeRune(buf[n:], phon)
+			code = append(code, a[i])
+		}
+		i++
+	}
+
+	return buf[:n], code
+}
+
+// stages returns encoding steps of enc for src before letters are
+// mapped to phonetic code. Rules which depend on harakat are skipped
+// if src is written without harakat.
+func (enc *Encoder) stages(src []byte, harakat bool) []phonetic.Stage {
+	var stages []phonetic.Stage
 	if enc.lettersMode == LettersUthmani {
-		b = NormalizedUthmani(src)
+		stages = append(stages, NormalizedUthmani)
+	} else {
+		stages = append(stages, NormalizedSimple)
+	}
+	if !HasHarakat(src) {
+		return append(stages,
+			Unvoweled,
+			RemoveSpace,
+			FixBoundary,
+			RemoveMadda,
+			RemoveHarakat,
+		)
 	}
-	b = RemoveSpace(b)
-	b = RemoveShadda(b)
-	b = JoinConsonant(b)
-	b = FixBoundary(b)
-	b = TanwinSub(b)
-	b = RemoveMadda(b)
-	b = RemoveUnreadConsonant(b)
-	b = IqlabSub(b)
-	b = IdghamSub(b)
-	if !enc.harakat {
-		b = RemoveHarakat(b)
+	stages = append(stages,
+		RemoveSpace,
+		RemoveShadda,
+		JoinConsonant,
+		FixBoundary,
+		TanwinSub,
+		RemoveMadda,
+		RemoveUnreadConsonant,
+		IqlabSub,
+		IdghamSub,
+	)
+	if !harakat {
+		stages = append(stages, RemoveHarakat)
 	}
-	b = Encode(b)
 
-	return b
+	return stages
 }
 
 // NormalizedUthmani ...
@@ -131,6 +193,121 @@ func NormalizedUthmani(b []byte) []byte {
 	return b
 }
 
+// NormalizedSimple normalizes simple script. Hamza and madda written as
+// combining marks are joined with their seat, alef and letters variants
+// are replaced by the simple letters, tatweel and quranic marks are
+// removed.
+func NormalizedSimple(b []byte) []byte {
+	runes := bytes.Runes(b)
+	buf := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		var seat, prev rune
+		if l := len(buf); l > 1 {
+			seat, prev = buf[l-1], buf[l-2]
+		} else if l > 0 {
+			seat = buf[l-1]
+		}
+		switch {
+		case r == ar.MaddahA && seat == ar.Alef && prev != ar.Fatha:
+			// madda after fatha only marks long vowel
+			buf[len(buf)-1] = ar.AlefMaddaA
+		case r == ar.HamzaA && seat == ar.Alef:
+			buf[len(buf)-1] = ar.AlefHamzaA
+		case r == ar.HamzaB && seat == ar.Alef:
+			buf[len(buf)-1] = ar.AlefHamzaB
+		case r == ar.HamzaA && seat == ar.Waw:
+			buf[len(buf)-1] = ar.WawHamzaA
+		case r == ar.HamzaA && (seat == ar.Yeh || seat == ar.AlefMaksura):
+			buf[len(buf)-1] = ar.YehHamzaA
+		case r == ar.HamzaA || r == ar.HamzaB:
+			buf = append(buf, ar.Hamza)
+		case r == ar.AlefWasla:
+			buf = append(buf, ar.Alef)
+		case r == ar.AlefWHamzaA:
+			buf = append(buf, ar.AlefHamzaA)
+		case r == ar.AlefWHamzaB:
+			buf = append(buf, ar.AlefHamzaB)
+		case r == ar.Keheh:
+			buf = append(buf, ar.Kaf)
+		case r == ar.FarsiYeh:
+			buf = append(buf, ar.Yeh)
+		case r == ar.Tatweel || r == ar.MaddahA || r == ar.AlefA ||
+			(r >= ar.SHLigatureSad && r <= ar.SLMeem):
+			// quranic marks
+		default:
+			buf = append(buf, r)
+		}
+	}
+
+	return []byte(string(buf))
+}
+
+// Unvoweled prepares text written without harakat. Alef starting a
+// word is read as hamza except for al after the first word, other alef
+// and lam of al before sun letters are not read, waw and yeh after a
+// consonant inside a word are read as long vowel unless they are the
+// second letter of the word as in qawl and bayt or carry sukun or shadda,
+// teh marbuta at the end is read as heh.
+func Unvoweled(b []byte) []byte {
+	runes := bytes.Runes(b)
+	buf := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		switch {
+		case r == ar.Alef && i == 0:
+			r = ar.AlefHamzaA
+		case r == ar.Alef:
+			if !unicode.IsSpace(runes[i-1]) || (i+1 < len(runes) && runes[i+1] == ar.Lam) {
+				continue
+			}
+			r = ar.AlefHamzaA
+		case r == ar.Lam && i > 0 && runes[i-1] == ar.Alef &&
+			(i == 1 || unicode.IsSpace(runes[i-2])) &&
+			i+1 < len(runes) && isSunLetter(runes[i+1]):
+			continue
+		case (r == ar.Waw || r == ar.Yeh) && i > 0 && isConsonant(runes[i-1]) &&
+			i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && isMadd(runes, i):
+			// madd like RemoveMadda drops, harakat before it is unknown
+			continue
+		}
+		buf = append(buf, r)
+	}
+	if l := len(buf); l > 0 && buf[l-1] == ar.TehMarbuta {
+		buf[l-1] = ar.Heh
+	}
+
+	return []byte(string(buf))
+}
+
+// isMadd reports whether waw or yeh at i after a consonant is read as
+// long vowel. The second letter of a word is read as diphthong, since
+// harakat of the first letter is unknown, as is letter of its own sukun
+// or shadda.
+func isMadd(runes []rune, i int) bool {
+	if i == 1 || unicode.IsSpace(runes[i-2]) {
+		return false
+	}
+	return runes[i+1] != ar.Sukun && runes[i+1] != ar.Shadda
+}
+
+// isConsonant reports whether r is a letter which is not alef, waw or yeh.
+func isConsonant(r rune) bool {
+	switch r {
+	case ar.Alef, ar.Waw, ar.Yeh, ar.AlefMaksura:
+		return false
+	}
+	_, ok := Mapping[r]
+	return ok && !isVowel(r)
+}
+
+func isSunLetter(r rune) bool {
+	switch r {
+	case ar.Teh, ar.Theh, ar.Dal, ar.Thal, ar.Reh, ar.Zain, ar.Seen,
+		ar.Sheen, ar.Sad, ar.Dad, ar.Tah, ar.Zah, ar.Lam, ar.Noon:
+		return true
+	}
+	return false
+}
+
 // RemoveSpace ...
 func RemoveSpace(b []byte) []byte {
 	return bytes.Map(func(r rune) rune {
@@ -188,6 +365,10 @@ func JoinConsonant(b []byte) []byte {
 func FixBoundary(b []byte) []byte {
 	runes := bytes.Runes(b)
 	l := len(runes)
+	if l < 2 {
+		// single letter has no boundary to fix
+		return b
+	}
 	r := runes[l-1]
 	if r == ar.Alef || r == ar.AlefMaksura {
 		// deletes if ended with alef / alef maksura (without harakat)
@@ -199,6 +380,9 @@ func FixBoundary(b []byte) []byte {
 	}
 
 	l = len(runes)
+	if l < 2 {
+		return []byte(string(runes))
+	}
 	r = runes[l-1]
 	if r == ar.Fathatan {
 		// if ended up with fathatan, substitute with fatha
@@ -247,6 +431,29 @@ func TanwinSub(b []byte) []byte {
 	return b
 }
 
+// IsArabic reports whether b is written in arabic script, that is b has
+// arabic letters and no latin letters.
+func IsArabic(b []byte) bool {
+	var arabic bool
+	for _, r := range string(b) {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			return false
+		case unicode.Is(unicode.Arabic, r) && unicode.IsLetter(r):
+			arabic = true
+		}
+	}
+	return arabic
+}
+
+// HasHarakat reports whether b has harakat or tanwin, so it can be
+// encoded with harakat.
+func HasHarakat(b []byte) bool {
+	return bytes.IndexFunc(b, func(r rune) bool {
+		return isHarakat(r) || isTanwin(r)
+	}) >= 0
+}
+
 func isHarakat(r rune) bool {
 	return r == ar.Fatha || r == ar.Kasra || r == ar.Damma
 }
@@ -293,9 +500,8 @@ func RemoveMadda(b []byte) []byte {
 	old := []byte(string(ar.AlefMaddaA))
 	r := []rune{ar.AlefHamzaA, ar.Fatha}
 	new := []byte(string(r))
-	buf = bytes.Replace(buf[:n], old, new, -1)
-
-	return buf[:n]
+	// replacement is longer than alef madda, so it is not cut to n
+	return bytes.Replace(buf[:n], old, new, -1)
 }
 
 // RemoveUnreadConsonant ...
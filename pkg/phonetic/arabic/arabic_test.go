@@ -31,6 +31,33 @@ func TestNormalizedUthmani(t *testing.T) {
 	}
 }
 
+func TestNormalizedSimple(t *testing.T) {
+	tables := []struct {
+		s        []byte
+		expected string
+	}{
+		// combining hamza and madda
+		{[]byte(string([]rune{ar.Alef, ar.HamzaA, ar.Alef, ar.HamzaB, ar.Waw, ar.HamzaA,
+			ar.Yeh, ar.HamzaA, ar.Alef, ar.MaddahA, ar.Beh, ar.HamzaA})),
+			string([]rune{ar.AlefHamzaA, ar.AlefHamzaB, ar.WawHamzaA, ar.YehHamzaA, ar.AlefMaddaA, ar.Beh, ar.Hamza})},
+		// madda after fatha
+		{[]byte(string([]rune{ar.Meem, ar.Fatha, ar.Alef, ar.MaddahA})),
+			string([]rune{ar.Meem, ar.Fatha, ar.Alef})},
+		// alef and letters variants
+		{[]byte(string([]rune{ar.AlefWasla, ar.AlefWHamzaA, ar.AlefWHamzaB, ar.Keheh, ar.FarsiYeh})),
+			string([]rune{ar.Alef, ar.AlefHamzaA, ar.AlefHamzaB, ar.Kaf, ar.Yeh})},
+		// tatweel and quranic marks
+		{[]byte("الرَّحْمَـٰنِ ۚ ذَٰلِكَ"), "الرَّحْمَنِ  ذَلِكَ"},
+	}
+
+	for _, table := range tables {
+		actual := string(arabic.NormalizedSimple(table.s))
+		if actual != table.expected {
+			t.Errorf("expected: %s, actual: %s", table.expected, actual)
+		}
+	}
+}
+
 func TestRemoveSpace(t *testing.T) {
 	tables := []struct {
 		s        []byte
@@ -125,6 +152,54 @@ func TestFixBoundary(t *testing.T) {
 	}
 }
 
+func TestUnvoweled(t *testing.T) {
+	tables := []struct {
+		s        []byte
+		expected string
+	}{
+		{[]byte("بسم الله الرحمن الرحيم"), "بسم له رحمن رحم"},
+		{[]byte("الحمد لله"), "ألحمد لله"},
+		{[]byte("قل هو الله احد"), "قل هو له أحد"},
+		{[]byte("رحمة"), "رحمه"},
+		{[]byte("مالك يوم الدين"), "ملك يوم دن"},
+		{[]byte("قل اعوذ برب الناس"), "قل أعذ برب نس"},
+		// diphthong, not long vowel
+		{[]byte("قول"), "قول"},
+		{[]byte("بيت"), "بيت"},
+		{[]byte("وقوْلهم"), "وقوْلهم"},
+		{[]byte("غير المغضوب"), "غير لمغضب"},
+	}
+
+	for _, table := range tables {
+		actual := string(arabic.Unvoweled(table.s))
+		if actual != table.expected {
+			t.Errorf("expected: %s, actual: %s", table.expected, actual)
+		}
+	}
+}
+
+func TestIsArabic(t *testing.T) {
+	tables := []struct {
+		s               string
+		arabic, harakat bool
+	}{
+		{"بِسْمِ ٱللَّهِ", true, true},
+		{"بسم الله", true, false},
+		{"bismillah", false, false},
+		{"bismillah بسم", false, false},
+		{"123", false, false},
+	}
+
+	for _, table := range tables {
+		if actual := arabic.IsArabic([]byte(table.s)); actual != table.arabic {
+			t.Errorf("%s is arabic error, expected: %v, actual: %v", table.s, table.arabic, actual)
+		}
+		if actual := arabic.HasHarakat([]byte(table.s)); actual != table.harakat {
+			t.Errorf("%s has harakat error, expected: %v, actual: %v", table.s, table.harakat, actual)
+		}
+	}
+}
+
 func TestTanwinSub(t *testing.T) {
 	tables := []struct {
 		s        []byte
@@ -164,6 +239,8 @@ func TestRemoveMadda(t *testing.T) {
 		{[]byte("لَرَءُوفٌ رَّحِيمٌ"), "لَرَءُفُنْرَحِمْ"},
 		// Al-Fatihah(1) verse: 5
 		{[]byte("إِيَّاكَ نَعْبُدُ وَإِيَّاكَ نَسْتَعِينُ"), "إِيَكَنَعْبُدُوَإِيَكَنَسْتَعِنْ"},
+		// alef madda above -> alef hamza above + fatha, keeps the rest
+		{[]byte("الَّذِينَ آمَنُوا"), "أَلَذِنَأَمَنُو"},
 	}
 
 	for _, table := range tables {
@@ -379,3 +456,86 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeSimple(t *testing.T) {
+	enc := arabic.NewEncoder(arabic.LettersSimple)
+	tables := []struct {
+		s        []byte
+		harakat  bool
+		expected string
+	}{
+		// Al-Fatihah(1) verse: 1-7 simple script
+		{[]byte("بِسْمِ اللَّهِ الرَّحْمَٰنِ الرَّحِيمِ"), false, "BSMLHRHMNRHM"},
+		{[]byte("الْحَمْدُ لِلَّهِ رَبِّ الْعَالَمِينَ"), false, "XLHMDLLHRBLXLMN"},
+		{[]byte("الرَّحْمَٰنِ الرَّحِيمِ"), true, "XARAHMANIRAHIM"},
+		{[]byte("مَالِكِ يَوْمِ الدِّينِ"), false, "MLKYWMDN"},
+		{[]byte("إِيَّاكَ نَعْبُدُ وَإِيَّاكَ نَسْتَعِينُ"), false, "XYKNXBDWXYKNSTXN"},
+		{[]byte("اهْدِنَا الصِّرَاطَ الْمُسْتَقِيمَ"), false, "XHDNSRTLMSTKM"},
+		{[]byte("صِرَاطَ الَّذِينَ أَنْعَمْتَ عَلَيْهِمْ غَيْرِ الْمَغْضُوبِ عَلَيْهِمْ وَلَا الضَّالِّينَ"), false, "SRTLZNXNXMTXLYHMGYRLMGDBXLYHMWLDLN"},
+		// Al-Baqarah(2) verse: 25, alef madda
+		{[]byte("الَّذِينَ آمَنُوا وَعَمِلُوا"), true, "XALAZINAXAMANUWAXAMILUW"},
+		{[]byte("الَّذِينَ آمَنُوا وَعَمِلُوا"), false, "XLZNXMNWXMLW"},
+		// without harakat
+		{[]byte("بسم الله الرحمن الرحيم"), false, "BSMLHRHMNRHM"},
+		{[]byte("بسم الله الرحمن الرحيم"), true, "BSMLHRHMNRHM"},
+		{[]byte("قل هو الله احد"), false, "KLHWLHXHD"},
+		{[]byte("الحمد لله رب العالمين"), false, "XLHMDLLHRBLXLMN"},
+		{[]byte("مالك يوم الدين"), false, "MLKYWMDN"},
+		{[]byte("قل اعوذ برب الناس"), false, "KLXXZBRBNS"},
+		{[]byte("قول"), false, "KWL"},
+		{[]byte("بيت"), false, "BYT"},
+		{[]byte("ب"), false, "B"},
+		{[]byte(""), false, ""},
+	}
+
+	for _, table := range tables {
+		actual := string(enc.Encode(table.s, table.harakat))
+		if actual != table.expected {
+			t.Errorf("%s error, expected: %s, actual: %s", table.s, table.expected, actual)
+		}
+	}
+}
+
+func TestParseLettersMode(t *testing.T) {
+	for _, expected := range []arabic.LettersMode{arabic.LettersSimple, arabic.LettersUthmani} {
+		actual, err := arabic.ParseLettersMode(expected.String())
+		if err != nil || actual != expected {
+			t.Errorf("expected: %v, actual: %v, %v", expected, actual, err)
+		}
+	}
+	if _, err := arabic.ParseLettersMode("imlaei"); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
+
+func TestEncodeWithOffsets(t *testing.T) {
+	enc := arabic.NewEncoder(arabic.LettersUthmani)
+	tables := []struct {
+		s        string
+		harakat  bool
+		expected []string
+	}{
+		// Al-Ikhlas(112) verse: 1
+		{"قُلْ هُوَ ٱللَّهُ أَحَدٌ", false, []string{"ق", "ل", "ه", "و", "ل", "ه", "أ", "ح", "د"}},
+		{"قُلْ هُوَ ٱللَّهُ أَحَدٌ", true, []string{"ق", "ُ", "ل", "ه", "ُ", "و", "َ", "ل", "َ", "ه", "ُ", "أ", "َ", "ح", "َ", "د"}},
+		// Al-Fatihah(1) verse: 2
+		{"ٱلْحَمْدُ لِلَّهِ", false, []string{"ٱ", "ل", "ح", "م", "د", "ل", "ل", "ه"}},
+	}
+
+	for _, table := range tables {
+		code, a := enc.EncodeWithOffsets([]byte(table.s), table.harakat)
+		if expected := enc.Encode([]byte(table.s), table.harakat); string(code) != string(expected) {
+			t.Errorf("expected: %s, actual: %s", expected, code)
+		}
+		a = a.Bytes([]byte(table.s))
+		if len(a) != len(table.expected) {
+			t.Errorf("expected: %d offsets, actual: %d offsets", len(table.expected), len(a))
+			continue
+		}
+		for i, expected := range table.expected {
+			if actual := table.s[a[i].Start:a[i].End]; actual != expected {
+				t.Errorf("code %d of %s error, expected: %s, actual: %s", i, code, expected, actual)
+			}
+		}
+	}
+}
@@ -9,18 +9,12 @@ import (
 	"github.com/dlclark/regexp2"
 )
 
-// Encoder implements indonesia-phonetic encoding.
-type Encoder struct {
-	vowel bool
-}
-
-// SetVowel ...
-func (enc *Encoder) SetVowel(vowel bool) {
-	enc.vowel = vowel
-}
+// Encoder implements indonesia-phonetic encoding. Encoder has no state
+// and is safe for concurrent use.
+type Encoder struct{}
 
 // Encode returns encoded of src using encoding enc.
-func (enc *Encoder) Encode(src []byte) []byte {
+func (enc Encoder) Encode(src []byte, vowel bool) []byte {
 	b := praprocess(src)
 	b = vowelSub(b)
 	b = joinConsonant(b)
@@ -33,7 +27,7 @@ func (enc *Encoder) Encode(src []byte) []byte {
 	b = encode1consonant(b)
 	b = encode2consonant(b)
 	b = removeSpace(b)
-	if !enc.vowel {
+	if !vowel {
 		b = removeVowel(b)
 	}
 
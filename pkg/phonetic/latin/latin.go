@@ -6,53 +6,65 @@ import (
 	"strings"
 
 	ar "github.com/billyzaelani/go-lafzi/pkg/arabic"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
 	"github.com/dlclark/regexp2"
 )
 
 // Encoder implements auto encoding from latin writing system to
 // phonetic. Encoding with vowel might resulting unexpected behavior
-// (future work).
+// (future work). Encoder is immutable and safe for concurrent use.
 type Encoder struct {
-	vowel bool
 	mapLetters
 }
 
-// NewEncoder ...
+// NewEncoder returns encoder using a copy of mapLetters.
 func NewEncoder(mapLetters map[rune]string) *Encoder {
+	m := make(map[rune]string, len(mapLetters))
+	for r, l := range mapLetters {
+		m[r] = l
+	}
 	return &Encoder{
-		mapLetters: mapLetters,
+		mapLetters: m,
 	}
 }
 
-// SetVowel ...
-func (enc *Encoder) SetVowel(vowel bool) {
-	enc.vowel = vowel
+// Encode returns encoded of src using encoding enc.
+func (enc *Encoder) Encode(src []byte, vowel bool) []byte {
+	b := src
+	for _, stage := range enc.stages(vowel) {
+		b = stage(b)
+	}
+
+	return b
 }
 
-// SetLettersMapping ...
-func (enc *Encoder) SetLettersMapping(mapLetters map[rune]string) {
-	enc.mapLetters = mapLetters
+// EncodeWithOffsets is like Encode but also returns alignment of every
+// phonetic code to the runes of src it came from.
+func (enc *Encoder) EncodeWithOffsets(src []byte, vowel bool) ([]byte, phonetic.Alignment) {
+	// uppercase maps rune to rune, runes of upper are runes of src
+	return phonetic.EncodeStages(bytes.ToUpper(src), enc.stages(vowel)...)
 }
 
-// Encode returns encoded of src using encoding enc.
-func (enc *Encoder) Encode(src []byte) []byte {
-	b := praprocess(src)
-	b = vowelSub(b)
-	b = enc.joinConsonant(b)
-	b = joinVowel(b)
-	b = diphthongSub(b)
-	// b = enc.joinAleefLam(b)
-	b = markHamzah(b)
-	b = enc.ikhfaSub(b)
-	b = enc.iqlabSub(b)
-	b = enc.idghamSub(b)
-	b = enc.encode(b)
-	b = removeSpace(b)
-	if !enc.vowel {
-		b = removeVowel(b)
+func (enc *Encoder) stages(vowel bool) []phonetic.Stage {
+	stages := []phonetic.Stage{
+		praprocess,
+		vowelSub,
+		enc.joinConsonant,
+		joinVowel,
+		diphthongSub,
+		// enc.joinAleefLam,
+		markHamzah,
+		enc.ikhfaSub,
+		enc.iqlabSub,
+		enc.idghamSub,
+		enc.encode,
+		removeSpace,
+	}
+	if !vowel {
+		stages = append(stages, removeVowel)
 	}
 
-	return b
+	return stages
 }
 
 func praprocess(b []byte) []byte {
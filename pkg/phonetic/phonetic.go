@@ -2,5 +2,7 @@ package phonetic
 
 // Encoder ...
 type Encoder interface {
-	Encode(src []byte) []byte
+	// Encode returns phonetic code of src, vowel is kept in phonetic
+	// code if vowel is true. Encode must be safe for concurrent use.
+	Encode(src []byte, vowel bool) []byte
 }
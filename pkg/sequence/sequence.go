@@ -11,13 +11,50 @@ import (
 
 // Order ...
 const (
-	X      = -1
-	maxGap = 7
+	X = -1
 )
 
-// Sequence ...
+// Config configures splitting sequence into subsequences and scoring
+// them.
+type Config struct {
+	// MaxGap splits sequence between adjacent positions at least MaxGap
+	// apart, it must be positive.
+	MaxGap int
+	// GapPenalty returns compactness of subsequence in range (0, 1] from
+	// differences of its adjacent positions, subsequence score is its
+	// length times compactness. Nil uses ReciprocalDifference.
+	GapPenalty func(gaps []int) float64
+	// MinRunLength is the minimum length of subsequence.
+	MinRunLength int
+}
+
+// DefaultConfig returns config for phonetic code with or without vowel,
+// phonetic code with vowel is about twice as long so is its gap.
+func DefaultConfig(vowel bool) Config {
+	if vowel {
+		return Config{MaxGap: 14, GapPenalty: ReciprocalDifference}
+	}
+	return Config{MaxGap: 7, GapPenalty: ReciprocalDifference}
+}
+
+// ReciprocalDifference returns mean of reciprocal of gaps, it is 1 for
+// no gaps.
+func ReciprocalDifference(gaps []int) float64 {
+	if len(gaps) == 0 {
+		return 1
+	}
+	var reciprocal float64
+	for _, gap := range gaps {
+		reciprocal += 1 / float64(gap)
+	}
+	return reciprocal / float64(len(gaps))
+}
+
+// Sequence is positions matched in order, its zero value is an empty
+// sequence. Subsequence sorts positions in place and subsequences share
+// them, so a sequence can be reused by Reset after its subsequences are
+// no longer used.
 type Sequence struct {
-	bootstrap [100]Int
 	ints
 	strCache string
 }
@@ -27,34 +64,56 @@ type Int struct {
 	Order, Int int
 }
 
-func (s *Sequence) init() {
-	s.ints = s.bootstrap[:0]
-}
-
 // Insert ...
 func (s *Sequence) Insert(order int, v ...int) {
 	s.resetStringCache()
-	if s.ints == nil {
-		s.init()
-	}
 	for _, x := range v {
 		s.ints = append(s.ints, Int{order, x})
 	}
 }
 
-// Subsequence ...
+// InsertSequence inserts positions of t shifted by offset, keeping their
+// order.
+func (s *Sequence) InsertSequence(t *Sequence, offset int) {
+	s.resetStringCache()
+	for _, x := range t.ints {
+		s.ints = append(s.ints, Int{x.Order, x.Int + offset})
+	}
+}
+
+// Reset empties s keeping its capacity.
+func (s *Sequence) Reset() {
+	s.resetStringCache()
+	s.ints = s.ints[:0]
+}
+
+// Subsequence returns subsequences of s at least minScore long split
+// by DefaultConfig without vowel, from the highest score.
 func (s *Sequence) Subsequence(minScore float64) []Subsequence {
+	c := DefaultConfig(false)
+	c.MinRunLength = int(math.Ceil(minScore))
+	return s.SubsequenceConfig(c)
+}
+
+// SubsequenceConfig returns subsequences of s split and scored by c, from
+// the highest score.
+func (s *Sequence) SubsequenceConfig(c Config) []Subsequence {
 	s.resetStringCache()
+	penalty := c.GapPenalty
+	if penalty == nil {
+		penalty = ReciprocalDifference
+	}
 	var (
-		min    = int(math.Ceil(minScore))
-		rawseq = s.split()
+		rawseq = s.split(c.MaxGap)
 		subseq = rawseq[:0]
+		// gaps of every subsequence share one buffer
+		buf = make([]int, len(s.ints))
 	)
 
 	for _, seq := range rawseq {
-		n := len(seq.sequence.ints)
-		if n >= min {
-			seq.score = float64(n) * seq.sequence.reciprocalDifference()
+		n := len(seq.ints)
+		if n >= c.MinRunLength {
+			seq.score = float64(n) * penalty(seq.ints.gaps(buf))
 			subseq = append(subseq, seq)
 		}
 	}
@@ -65,70 +124,45 @@ func (s *Sequence) Subsequence(minScore float64) []Subsequence {
 	return subseq
 }
 
-func (s *Sequence) split() []Subsequence {
+// split sorts s by position and splits it where positions are at least
+// maxGap apart or order does not increase.
+func (s *Sequence) split(maxGap int) []Subsequence {
+	n := len(s.ints)
+	if n == 0 {
+		return nil
+	}
+	sort.Sort(s.ints)
+
 	var (
-		n      = len(s.ints)
-		v      = make(ints, n, n+1)
-		length = cap(v)
-		subseq = make([]Subsequence, 0)
+		subseq []Subsequence
 		start  = 0
-		order  = v[0].Order
+		order  = 0
 	)
-	copy(v, s.ints)
-	sort.Sort(v)
-	v = append(v, Int{})
-
-	for i := 1; i < length; i++ {
-		gap := v[i].Int - v[i-1].Int
+	for i := 1; i < n; i++ {
+		gap := s.ints[i].Int - s.ints[i-1].Int
 		if gap < maxGap {
-			if v[i].Order == X {
+			if s.ints[i].Order == X {
 				continue
 			}
 		}
-		if gap >= maxGap || v[i].Order <= order {
-			var seq Sequence
-			seq.init()
-			seq.ints = append(seq.ints, v[start:i]...)
-			subseq = append(subseq, Subsequence{sequence: seq})
+		if gap >= maxGap || s.ints[i].Order <= order {
+			subseq = append(subseq, Subsequence{ints: s.ints[start:i:i]})
 			start = i
 		}
-		order = v[i].Order
+		order = s.ints[i].Order
 	}
+	subseq = append(subseq, Subsequence{ints: s.ints[start:n:n]})
 
 	return subseq
 }
 
-func (s *Sequence) reciprocalDifference() (reciprocal float64) {
-	n := len(s.ints)
-
-	if n == 1 {
-		return 1
-	}
-
-	for i := 0; i < n-1; i++ {
-		reciprocal += (1 / float64(s.ints[i+1].Int-s.ints[i].Int))
-	}
-
-	return reciprocal / float64(n-1)
-}
-
 func (s *Sequence) resetStringCache() {
 	s.strCache = ""
 }
 
 func (s *Sequence) String() string {
 	if s.strCache == "" {
-		var str strings.Builder
-		n := len(s.ints)
-		str.WriteByte('[')
-		for i := 0; i < n; i++ {
-			if i > 0 {
-				str.WriteByte(' ')
-			}
-			str.WriteString(strconv.Itoa(s.ints[i].Int))
-		}
-		str.WriteByte(']')
-		s.strCache = str.String()
+		s.strCache = s.ints.String()
 	}
 
 	return s.strCache
@@ -148,6 +182,31 @@ func (x ints) Swap(i, j int) {
 	x[i], x[j] = x[j], x[i]
 }
 
+// gaps returns differences of adjacent positions of x in buf.
+func (x ints) gaps(buf []int) []int {
+	if len(x) < 2 {
+		return nil
+	}
+	gaps := buf[:len(x)-1]
+	for i := range gaps {
+		gaps[i] = x[i+1].Int - x[i].Int
+	}
+	return gaps
+}
+
+func (x ints) String() string {
+	var str strings.Builder
+	str.WriteByte('[')
+	for i, v := range x {
+		if i > 0 {
+			str.WriteByte(' ')
+		}
+		str.WriteString(strconv.Itoa(v.Int))
+	}
+	str.WriteByte(']')
+	return str.String()
+}
+
 // LIS (deprecated) finds longest increasing subsequence (LIS) in s.
 func LIS(s []int) Sequence {
 	var l, k int
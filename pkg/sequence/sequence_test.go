@@ -0,0 +1,62 @@
+package sequence_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/billyzaelani/go-lafzi/pkg/sequence"
+)
+
+func TestSubsequenceConfig(t *testing.T) {
+	var s sequence.Sequence
+	s.Insert(1, 1)
+	s.Insert(2, 2)
+	s.Insert(3, 3)
+	s.Insert(4, 12)
+	s.Insert(5, 13)
+
+	last := func(gaps []int) float64 {
+		if len(gaps) == 0 {
+			return 1
+		}
+		return 1 / float64(gaps[len(gaps)-1])
+	}
+	tables := []struct {
+		config    sequence.Config
+		positions [][]int
+		scores    []float64
+	}{
+		{sequence.DefaultConfig(false), [][]int{{1, 2, 3}, {12, 13}}, []float64{3, 2}},
+		{sequence.DefaultConfig(true), [][]int{{1, 2, 3, 12, 13}}, []float64{5 * (1 + 1 + 1.0/9 + 1) / 4}},
+		{sequence.Config{MaxGap: 7, MinRunLength: 3}, [][]int{{1, 2, 3}}, []float64{3}},
+		{sequence.Config{MaxGap: 10, GapPenalty: last}, [][]int{{1, 2, 3, 12, 13}}, []float64{5}},
+	}
+
+	for _, table := range tables {
+		subseq := s.SubsequenceConfig(table.config)
+		if len(subseq) != len(table.positions) {
+			t.Errorf("config: %+v error, expected: %v, actual: %v", table.config, table.positions, subseq)
+			continue
+		}
+		for i, ss := range subseq {
+			if !reflect.DeepEqual(table.positions[i], ss.Positions()) {
+				t.Errorf("expected: %v, actual: %v", table.positions[i], ss.Positions())
+			}
+			if ss.Score() != table.scores[i] {
+				t.Errorf("expected: %v, actual: %v", table.scores[i], ss.Score())
+			}
+		}
+	}
+}
+
+func BenchmarkSubsequence(b *testing.B) {
+	var s sequence.Sequence
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Reset()
+		for order := 1; order <= 40; order++ {
+			s.Insert(order, order*3%50, order*5)
+		}
+		s.SubsequenceConfig(sequence.DefaultConfig(false))
+	}
+}
@@ -2,8 +2,8 @@ package sequence
 
 // Subsequence ...
 type Subsequence struct {
-	sequence Sequence
-	score    float64
+	ints  ints
+	score float64
 }
 
 // Score ...
@@ -12,5 +12,14 @@ func (s Subsequence) Score() float64 {
 }
 
 func (s Subsequence) String() string {
-	return s.sequence.String()
+	return s.ints.String()
+}
+
+// Positions returns the positions in subsequence in ascending order.
+func (s Subsequence) Positions() []int {
+	pos := make([]int, len(s.ints))
+	for i, x := range s.ints {
+		pos[i] = x.Int
+	}
+	return pos
 }
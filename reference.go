@@ -0,0 +1,335 @@
+package lafzi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// VerseCount is the number of verses of the Quran.
+const VerseCount = 6236
+
+// verseCount is the number of verses of every chapter.
+var verseCount = [ChapterCount]int{
+	7, 286, 200, 176, 120, 165, 206, 75, 129, 109, 123, 111,
+	43, 52, 99, 128, 111, 110, 98, 135, 112, 78, 118, 64,
+	77, 227, 93, 88, 69, 60, 34, 30, 73, 54, 45, 83,
+	182, 88, 75, 85, 54, 53, 89, 59, 37, 35, 38, 29,
+	18, 45, 60, 49, 62, 55, 78, 96, 29, 22, 24, 13,
+	14, 11, 11, 18, 12, 12, 30, 52, 52, 44, 28, 28,
+	20, 56, 40, 31, 50, 40, 46, 42, 29, 19, 36, 25,
+	22, 17, 19, 26, 30, 20, 15, 21, 11, 8, 8, 19,
+	5, 8, 8, 11, 11, 8, 3, 9, 5, 4, 7, 3,
+	6, 3, 5, 4, 5, 6,
+}
+
+// chapterNames are latin and arabic names of every chapter.
+var chapterNames = [ChapterCount][2]string{
+	{"Al-Fatihah", "الفاتحة"},
+	{"Al-Baqarah", "البقرة"},
+	{"Ali-Imran", "آل عمران"},
+	{"An-Nisa", "النساء"},
+	{"Al-Ma'idah", "المائدة"},
+	{"Al-An'am", "الأنعام"},
+	{"Al-A'raf", "الأعراف"},
+	{"Al-Anfal", "الأنفال"},
+	{"At-Taubah", "التوبة"},
+	{"Yunus", "يونس"},
+	{"Hud", "هود"},
+	{"Yusuf", "يوسف"},
+	{"Ar-Ra'd", "الرعد"},
+	{"Ibrahim", "إبراهيم"},
+	{"Al-Hijr", "الحجر"},
+	{"An-Nahl", "النحل"},
+	{"Al-Isra", "الإسراء"},
+	{"Al-Kahfi", "الكهف"},
+	{"Maryam", "مريم"},
+	{"Ta-Ha", "طه"},
+	{"Al-Anbiya", "الأنبياء"},
+	{"Al-Hajj", "الحج"},
+	{"Al-Mu'minun", "المؤمنون"},
+	{"An-Nur", "النور"},
+	{"Al-Furqan", "الفرقان"},
+	{"Asy-Syu'ara", "الشعراء"},
+	{"An-Naml", "النمل"},
+	{"Al-Qashash", "القصص"},
+	{"Al-Ankabut", "العنكبوت"},
+	{"Ar-Rum", "الروم"},
+	{"Luqman", "لقمان"},
+	{"As-Sajdah", "السجدة"},
+	{"Al-Ahzab", "الأحزاب"},
+	{"Saba", "سبأ"},
+	{"Fathir", "فاطر"},
+	{"Yasin", "يس"},
+	{"As-Saffat", "الصافات"},
+	{"Sad", "ص"},
+	{"Az-Zumar", "الزمر"},
+	{"Ghafir", "غافر"},
+	{"Fusshilat", "فصلت"},
+	{"Asy-Syura", "الشورى"},
+	{"Az-Zukhruf", "الزخرف"},
+	{"Ad-Dukhan", "الدخان"},
+	{"Al-Jatsiyah", "الجاثية"},
+	{"Al-Ahqaf", "الأحقاف"},
+	{"Muhammad", "محمد"},
+	{"Al-Fath", "الفتح"},
+	{"Al-Hujurat", "الحجرات"},
+	{"Qaf", "ق"},
+	{"Adz-Dzariyat", "الذاريات"},
+	{"At-Tur", "الطور"},
+	{"An-Najm", "النجم"},
+	{"Al-Qamar", "القمر"},
+	{"Ar-Rahman", "الرحمن"},
+	{"Al-Waqi'ah", "الواقعة"},
+	{"Al-Hadid", "الحديد"},
+	{"Al-Mujadilah", "المجادلة"},
+	{"Al-Hasyr", "الحشر"},
+	{"Al-Mumtahanah", "الممتحنة"},
+	{"As-Saff", "الصف"},
+	{"Al-Jumu'ah", "الجمعة"},
+	{"Al-Munafiqun", "المنافقون"},
+	{"At-Taghabun", "التغابن"},
+	{"At-Talaq", "الطلاق"},
+	{"At-Tahrim", "التحريم"},
+	{"Al-Mulk", "الملك"},
+	{"Al-Qalam", "القلم"},
+	{"Al-Haqqah", "الحاقة"},
+	{"Al-Ma'arij", "المعارج"},
+	{"Nuh", "نوح"},
+	{"Al-Jinn", "الجن"},
+	{"Al-Muzzammil", "المزمل"},
+	{"Al-Muddatsir", "المدثر"},
+	{"Al-Qiyamah", "القيامة"},
+	{"Al-Insan", "الإنسان"},
+	{"Al-Mursalat", "المرسلات"},
+	{"An-Naba'", "النبأ"},
+	{"An-Nazi'at", "النازعات"},
+	{"Abasa", "عبس"},
+	{"At-Takwir", "التكوير"},
+	{"Al-Infithar", "الانفطار"},
+	{"Al-Muthaffifin", "المطففين"},
+	{"Al-Insyiqaq", "الانشقاق"},
+	{"Al-Buruj", "البروج"},
+	{"At-Tariq", "الطارق"},
+	{"Al-A'la", "الأعلى"},
+	{"Al-Ghasyiyah", "الغاشية"},
+	{"Al-Fajr", "الفجر"},
+	{"Al-Balad", "البلد"},
+	{"Asy-Syams", "الشمس"},
+	{"Al-Lail", "الليل"},
+	{"Ad-Dhuha", "الضحى"},
+	{"Al-Insyirah", "الشرح"},
+	{"At-Tin", "التين"},
+	{"Al-Alaq", "العلق"},
+	{"Al-Qadr", "القدر"},
+	{"Al-Bayyinah", "البينة"},
+	{"Az-Zalzalah", "الزلزلة"},
+	{"Al-Adiyat", "العاديات"},
+	{"Al-Qari'ah", "القارعة"},
+	{"At-Takatsur", "التكاثر"},
+	{"Al-Asr", "العصر"},
+	{"Al-Humazah", "الهمزة"},
+	{"Al-Fil", "الفيل"},
+	{"Quraysy", "قريش"},
+	{"Al-Ma'un", "الماعون"},
+	{"Al-Kautsar", "الكوثر"},
+	{"Al-Kafirun", "الكافرون"},
+	{"An-Nasr", "النصر"},
+	{"Al-Masad", "المسد"},
+	{"Al-Ikhlas", "الإخلاص"},
+	{"Al-Falaq", "الفلق"},
+	{"An-Nas", "الناس"},
+}
+
+// chapterStart is ID of the first verse of every chapter.
+var chapterStart = func() (start [ChapterCount]ID) {
+	id := 1
+	for i, n := range verseCount {
+		start[i] = id
+		id += n
+	}
+	return start
+}()
+
+// chapterByName maps normalized names of chapters, with and without
+// article, to chapter number.
+var chapterByName = func() map[string]int {
+	m := make(map[string]int, 4*ChapterCount)
+	for i, names := range chapterNames {
+		for _, name := range names {
+			for _, alias := range nameAliases(name) {
+				m[alias] = i + 1
+			}
+		}
+	}
+	return m
+}()
+
+// Reference is a verse, an inclusive range of verses from From to To of
+// a chapter, or a whole chapter if From is zero.
+type Reference struct {
+	Chapter  int
+	From, To int
+}
+
+// ParseReference parses reference written as chapter number or name
+// followed by optional verse or range of verses, such as "2:255",
+// "2:1-5", "Al-Baqarah 255", "al baqarah", "Yasin 1-12" or "البقرة 255".
+// Latin names are those of ChapterName.
+func ParseReference(s string) (Reference, error) {
+	chapter, verses := splitReference(s)
+	var r Reference
+	if n, err := strconv.Atoi(chapter); err == nil {
+		r.Chapter = n
+	} else {
+		r.Chapter = chapterByName[normalizeName(chapter)]
+	}
+	if r.Chapter < 1 || r.Chapter > ChapterCount {
+		return Reference{}, fmt.Errorf("lafzi: invalid reference %q, unknown chapter", s)
+	}
+	if verses == "" {
+		return r, nil
+	}
+
+	from, to := verses, verses
+	if i := strings.IndexAny(verses, "-–"); i >= 0 {
+		from, to = verses[:i], strings.TrimLeft(verses[i:], "-–")
+	}
+	var err error
+	if r.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return Reference{}, fmt.Errorf("lafzi: invalid reference %q", s)
+	}
+	if r.To, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || r.From < 1 {
+		return Reference{}, fmt.Errorf("lafzi: invalid reference %q", s)
+	}
+	if err := r.validate(); err != nil {
+		return Reference{}, fmt.Errorf("lafzi: invalid reference %q, %v", s, err)
+	}
+	return r, nil
+}
+
+// splitReference splits s into chapter and verses, verses are after colon
+// or the last word if it starts with a digit.
+func splitReference(s string) (chapter, verses string) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	for _, prefix := range []string{"surah ", "surat ", "qs ", "q.s. ", "سورة "} {
+		if strings.HasPrefix(lower, prefix) {
+			s = strings.TrimSpace(s[len(prefix):])
+			break
+		}
+	}
+	if i := strings.LastIndexByte(s, ':'); i >= 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	}
+	if i := strings.LastIndexFunc(s, unicode.IsSpace); i >= 0 && i+1 < len(s) && isDigit(s[i+1]) {
+		return strings.TrimSpace(s[:i]), s[i+1:]
+	}
+	return s, ""
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// nameAliases returns normalized name, and name without article if it
+// has one, like Al- of Al-Baqarah or ال of البقرة.
+func nameAliases(name string) []string {
+	aliases := []string{normalizeName(name)}
+	if i := strings.IndexByte(name, '-'); i > 0 && i <= 3 && (name[0] == 'A' || name[0] == 'a') {
+		aliases = append(aliases, normalizeName(name[i+1:]))
+	}
+	if strings.HasPrefix(name, "ال") && len(name) > len("ال")+2 {
+		aliases = append(aliases, normalizeName(strings.TrimPrefix(name, "ال")))
+	}
+	return aliases
+}
+
+// normalizeName returns lower case letters of name, arabic letters are
+// written without harakat and with plain alef, yeh and heh.
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch r {
+		case 'أ', 'إ', 'آ', 'ٱ':
+			r = 'ا'
+		case 'ى':
+			r = 'ي'
+		case 'ة':
+			r = 'ه'
+		}
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// validate returns error if r is not a verse or range of verses of a
+// chapter of the Quran.
+func (r Reference) validate() error {
+	if r.Chapter < 1 || r.Chapter > ChapterCount {
+		return fmt.Errorf("chapter %d out of range", r.Chapter)
+	}
+	if r.From == 0 && r.To == 0 {
+		return nil
+	}
+	if n := verseCount[r.Chapter-1]; r.From < 1 || r.To > n || r.From > r.To {
+		return fmt.Errorf("verses %d-%d out of range 1-%d", r.From, r.To, n)
+	}
+	return nil
+}
+
+// IDs returns ID of the first and the last verse of r.
+func (r Reference) IDs() (first, last ID, err error) {
+	if err := r.validate(); err != nil {
+		return 0, 0, fmt.Errorf("lafzi: reference %s: %v", r, err)
+	}
+	start := chapterStart[r.Chapter-1]
+	if r.From == 0 {
+		return start, start + verseCount[r.Chapter-1] - 1, nil
+	}
+	return start + r.From - 1, start + r.To - 1, nil
+}
+
+// ReferenceOf returns reference of verse id.
+func ReferenceOf(id ID) (Reference, error) {
+	if id < 1 || id > VerseCount {
+		return Reference{}, fmt.Errorf("lafzi: id %d out of range 1-%d", id, VerseCount)
+	}
+	i := sort.Search(ChapterCount, func(i int) bool {
+		return chapterStart[i] > id
+	}) - 1
+	verse := id - chapterStart[i] + 1
+	return Reference{Chapter: i + 1, From: verse, To: verse}, nil
+}
+
+// Len returns the number of verses of r.
+func (r Reference) Len() int {
+	if r.From == 0 && r.Chapter >= 1 && r.Chapter <= ChapterCount {
+		return verseCount[r.Chapter-1]
+	}
+	return r.To - r.From + 1
+}
+
+func (r Reference) String() string {
+	switch {
+	case r.From == 0:
+		return strconv.Itoa(r.Chapter)
+	case r.From == r.To:
+		return fmt.Sprintf("%d:%d", r.Chapter, r.From)
+	default:
+		return fmt.Sprintf("%d:%d-%d", r.Chapter, r.From, r.To)
+	}
+}
+
+// ChapterName returns latin and arabic name of chapter.
+func ChapterName(chapter int) (latin, arabic string, err error) {
+	if chapter < 1 || chapter > ChapterCount {
+		return "", "", fmt.Errorf("lafzi: chapter %d out of range", chapter)
+	}
+	names := chapterNames[chapter-1]
+	return names[0], names[1], nil
+}
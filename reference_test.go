@@ -0,0 +1,89 @@
+package lafzi_test
+
+import (
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+)
+
+func TestParseReference(t *testing.T) {
+	tables := []struct {
+		s        string
+		expected lafzi.Reference
+		err      bool
+	}{
+		{"2:255", lafzi.Reference{Chapter: 2, From: 255, To: 255}, false},
+		{"2:1-5", lafzi.Reference{Chapter: 2, From: 1, To: 5}, false},
+		{"2:1–5", lafzi.Reference{Chapter: 2, From: 1, To: 5}, false},
+		{"36", lafzi.Reference{Chapter: 36}, false},
+		{"Al-Baqarah 255", lafzi.Reference{Chapter: 2, From: 255, To: 255}, false},
+		{"al baqarah:1-5", lafzi.Reference{Chapter: 2, From: 1, To: 5}, false},
+		{"Surat Baqarah", lafzi.Reference{Chapter: 2}, false},
+		{"QS Ali Imran 7", lafzi.Reference{Chapter: 3, From: 7, To: 7}, false},
+		{"Yasin 1-12", lafzi.Reference{Chapter: 36, From: 1, To: 12}, false},
+		{"ta-ha", lafzi.Reference{Chapter: 20}, false},
+		{"البقرة 255", lafzi.Reference{Chapter: 2, From: 255, To: 255}, false},
+		{"سورة الإخلاص", lafzi.Reference{Chapter: 112}, false},
+		{"آل عمران:7", lafzi.Reference{Chapter: 3, From: 7, To: 7}, false},
+		{"115", lafzi.Reference{}, true},
+		{"2:287", lafzi.Reference{}, true},
+		{"2:5-1", lafzi.Reference{}, true},
+		{"2:0", lafzi.Reference{}, true},
+		{"Al-Xyz 1", lafzi.Reference{}, true},
+		{"", lafzi.Reference{}, true},
+	}
+
+	for _, table := range tables {
+		actual, err := lafzi.ParseReference(table.s)
+		if table.err {
+			if err == nil {
+				t.Errorf("reference: %s error, expected: error, actual: %+v", table.s, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("reference: %s error, expected: %+v, actual: %v", table.s, table.expected, err)
+		} else if actual != table.expected {
+			t.Errorf("reference: %s error, expected: %+v, actual: %+v", table.s, table.expected, actual)
+		}
+	}
+}
+
+func TestReferenceIDs(t *testing.T) {
+	tables := []struct {
+		ref         lafzi.Reference
+		first, last lafzi.ID
+	}{
+		{lafzi.Reference{Chapter: 1}, 1, 7},
+		{lafzi.Reference{Chapter: 2, From: 255, To: 255}, 262, 262},
+		{lafzi.Reference{Chapter: 2, From: 1, To: 5}, 8, 12},
+		{lafzi.Reference{Chapter: 114}, 6231, 6236},
+	}
+
+	for _, table := range tables {
+		first, last, err := table.ref.IDs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first != table.first || last != table.last {
+			t.Errorf("reference: %s error, expected: %d-%d, actual: %d-%d", table.ref, table.first, table.last, first, last)
+		}
+		ref, err := lafzi.ReferenceOf(first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := (lafzi.Reference{Chapter: table.ref.Chapter, From: ref.From, To: ref.From}); ref != expected ||
+			table.ref.From != 0 && ref.From != table.ref.From {
+			t.Errorf("id: %d error, expected: %s, actual: %s", first, table.ref, ref)
+		}
+	}
+
+	if _, _, err := (lafzi.Reference{Chapter: 1, From: 8, To: 8}).IDs(); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+	for _, id := range []lafzi.ID{0, 6237} {
+		if _, err := lafzi.ReferenceOf(id); err == nil {
+			t.Errorf("id: %d error, expected: error, actual: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
+)
+
+// Span is a half-open range [Start, End) of bytes in Ayat.Arabic.
+type Span struct {
+	Start, End int
+}
+
+// highlight fills HighlightPosition and Highlight of doc from its best
+// subsequence. Words of Ayat.Arabic are highlighted when any rune of
+// them is aligned to a highlighted phonetic code by enc, every verse of
+// document spanning verses is aligned alone.
+func highlight(doc *Document, enc phonetic.OffsetEncoder, vowel bool) {
+	if len(doc.Subsequence) == 0 {
+		return
+	}
+	// trigram at position p covers phonetic code p, p+1 and p+2
+	pos := doc.Subsequence[0].Positions()
+	doc.HighlightPosition = make([]int, 0, len(pos)+2)
+	for _, p := range pos {
+		for i := p; i < p+3; i++ {
+			if n := len(doc.HighlightPosition); n == 0 || doc.HighlightPosition[n-1] < i {
+				doc.HighlightPosition = append(doc.HighlightPosition, i)
+			}
+		}
+	}
+
+	verses := doc.verses
+	if verses == nil {
+		verses = []verseOffset{{}}
+	}
+	var words []Span
+	var marked []bool
+	for i, v := range verses {
+		end := len(doc.Arabic)
+		if i+1 < len(verses) {
+			end = verses[i+1].arabic - len(verseSeparator)
+		}
+		src := []byte(doc.Arabic[v.arabic:end])
+		_, a := enc.EncodeWithOffsets(src, vowel)
+		a = a.Bytes(src)
+		n := len(words)
+		for _, w := range wordSpans(string(src)) {
+			words = append(words, Span{w.Start + v.arabic, w.End + v.arabic})
+			marked = append(marked, false)
+		}
+		for _, p := range doc.HighlightPosition {
+			p -= v.phonetic
+			if p < 1 {
+				continue
+			}
+			if p > len(a) {
+				break
+			}
+			r := Span{a[p-1].Start + v.arabic, a[p-1].End + v.arabic}
+			for j, w := range words[n:] {
+				if r.Start < w.End && w.Start < r.End {
+					marked[n+j] = true
+				}
+			}
+		}
+	}
+
+	var hl []Span
+	for i, w := range words {
+		if !marked[i] {
+			continue
+		}
+		if n := len(hl); n > 0 && adjacent(doc.Arabic, hl[n-1].End, w.Start) {
+			hl[n-1].End = w.End
+		} else {
+			hl = append(hl, w)
+		}
+	}
+	doc.Highlight = hl
+}
+
+// wordSpans returns byte spans of space separated words of s.
+func wordSpans(s string) []Span {
+	var spans []Span
+	start := -1
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				spans = append(spans, Span{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, Span{start, len(s)})
+	}
+	return spans
+}
+
+// adjacent reports whether s between from and to only contains spaces
+// and marks which encode to nothing.
+func adjacent(s string, from, to int) bool {
+	return strings.TrimFunc(s[from:to], func(r rune) bool {
+		return unicode.IsSpace(r) || !unicode.IsLetter(r)
+	}) == ""
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+)
+
+// Division is a way of dividing the Quran into numbered parts.
+type Division int
+
+// Divisions of the Quran.
+const (
+	Chapter Division = iota
+	Juz
+	Hizb
+)
+
+// count returns the number of parts of d.
+func (d Division) count() int {
+	switch d {
+	case Juz:
+		return lafzi.JuzCount
+	case Hizb:
+		return lafzi.HizbCount
+	default:
+		return lafzi.ChapterCount
+	}
+}
+
+func (d Division) String() string {
+	switch d {
+	case Juz:
+		return "juz"
+	case Hizb:
+		return "hizb"
+	default:
+		return "chapter"
+	}
+}
+
+// Scope is an inclusive range of parts of Division from From to To. Scope
+// of a single chapter may be narrowed to an inclusive range of verses
+// from FromVerse to ToVerse, zero ToVerse means until the last verse.
+type Scope struct {
+	Division
+	From, To           int
+	FromVerse, ToVerse int
+}
+
+// Contains reports whether verse of info is in s.
+func (s Scope) Contains(info lafzi.Info) bool {
+	var part int
+	switch s.Division {
+	case Juz:
+		part = info.Juz
+	case Hizb:
+		part = info.Hizb
+	default:
+		part = info.ChapterNo
+	}
+	if part < s.From || part > s.To {
+		return false
+	}
+	if s.FromVerse > 0 && info.VerseNo < s.FromVerse {
+		return false
+	}
+	return s.ToVerse == 0 || info.VerseNo <= s.ToVerse
+}
+
+// inScope reports whether verse of info is in any of scopes, every verse
+// is in empty scopes.
+func inScope(scopes []Scope, info lafzi.Info) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s.Contains(info) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScope parses scope of d written as a part such as "2", a range of
+// parts such as "29-30", or for Chapter also a verse such as "2:255" and a
+// range of verses such as "2:1-5".
+func ParseScope(d Division, str string) (Scope, error) {
+	s := Scope{Division: d}
+	parts, verses := str, ""
+	if i := strings.IndexByte(str, ':'); i >= 0 {
+		if d != Chapter {
+			return s, fmt.Errorf("search: invalid %s scope %q, only chapter has verses", d, str)
+		}
+		parts, verses = str[:i], str[i+1:]
+	}
+
+	var err error
+	if s.From, s.To, err = parseRange(parts); err != nil || s.From < 1 || s.To > d.count() {
+		return s, fmt.Errorf("search: invalid %s scope %q", d, str)
+	}
+	if verses != "" {
+		if s.From != s.To {
+			return s, fmt.Errorf("search: invalid %s scope %q, verses of multiple chapters", d, str)
+		}
+		if s.FromVerse, s.ToVerse, err = parseRange(verses); err != nil || s.FromVerse < 1 {
+			return s, fmt.Errorf("search: invalid %s scope %q", d, str)
+		}
+	}
+	return s, nil
+}
+
+// parseRange parses "n" or "from-to" where from is not greater than to.
+func parseRange(str string) (from, to int, err error) {
+	fromStr, toStr := str, str
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		fromStr, toStr = str[:i], str[i+1:]
+	}
+	if from, err = strconv.Atoi(strings.TrimSpace(fromStr)); err != nil {
+		return 0, 0, err
+	}
+	if to, err = strconv.Atoi(strings.TrimSpace(toStr)); err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("range %q is reversed", str)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,68 @@
+package search_test
+
+import (
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/search"
+)
+
+func TestParseScope(t *testing.T) {
+	tables := []struct {
+		division search.Division
+		str      string
+		expected search.Scope
+		err      bool
+	}{
+		{search.Chapter, "2", search.Scope{Division: search.Chapter, From: 2, To: 2}, false},
+		{search.Chapter, "1-3", search.Scope{Division: search.Chapter, From: 1, To: 3}, false},
+		{search.Chapter, "2:255", search.Scope{Division: search.Chapter, From: 2, To: 2, FromVerse: 255, ToVerse: 255}, false},
+		{search.Chapter, "2:1-5", search.Scope{Division: search.Chapter, From: 2, To: 2, FromVerse: 1, ToVerse: 5}, false},
+		{search.Juz, "29-30", search.Scope{Division: search.Juz, From: 29, To: 30}, false},
+		{search.Hizb, "60", search.Scope{Division: search.Hizb, From: 60, To: 60}, false},
+		{search.Chapter, "115", search.Scope{}, true},
+		{search.Chapter, "0", search.Scope{}, true},
+		{search.Chapter, "3-1", search.Scope{}, true},
+		{search.Chapter, "1-2:3", search.Scope{}, true},
+		{search.Chapter, "2:5-1", search.Scope{}, true},
+		{search.Juz, "31", search.Scope{}, true},
+		{search.Juz, "30:1", search.Scope{}, true},
+		{search.Hizb, "x", search.Scope{}, true},
+	}
+
+	for _, table := range tables {
+		actual, err := search.ParseScope(table.division, table.str)
+		if table.err {
+			if err == nil {
+				t.Errorf("scope: %s error, expected: error, actual: %+v", table.str, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("scope: %s error, expected: %+v, actual: %v", table.str, table.expected, err)
+		} else if actual != table.expected {
+			t.Errorf("scope: %s error, expected: %+v, actual: %+v", table.str, table.expected, actual)
+		}
+	}
+}
+
+func TestScopeContains(t *testing.T) {
+	info := lafzi.Info{ChapterNo: 2, VerseNo: 255, Juz: 3, Hizb: 5}
+	tables := []struct {
+		scope    search.Scope
+		expected bool
+	}{
+		{search.Scope{Division: search.Chapter, From: 2, To: 2}, true},
+		{search.Scope{Division: search.Chapter, From: 3, To: 114}, false},
+		{search.Scope{Division: search.Chapter, From: 2, To: 2, FromVerse: 255}, true},
+		{search.Scope{Division: search.Chapter, From: 2, To: 2, FromVerse: 1, ToVerse: 254}, false},
+		{search.Scope{Division: search.Juz, From: 3, To: 3}, true},
+		{search.Scope{Division: search.Hizb, From: 6, To: 60}, false},
+	}
+
+	for _, table := range tables {
+		if actual := table.scope.Contains(info); actual != table.expected {
+			t.Errorf("scope: %+v error, expected: %v, actual: %v", table.scope, table.expected, actual)
+		}
+	}
+}
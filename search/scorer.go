@@ -0,0 +1,263 @@
+package search
+
+import (
+	"fmt"
+	"math"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	seq "github.com/billyzaelani/go-lafzi/pkg/sequence"
+	"github.com/billyzaelani/go-lafzi/pkg/trigram"
+)
+
+// Scorer scores documents matched by query trigrams, documents are
+// ranked by descending score. Scorer must be safe for concurrent use.
+type Scorer interface {
+	// Score returns score of doc matched by q.
+	Score(q *Query, doc *Document) float64
+	// MaxScore returns score of document matching every trigram of q,
+	// filter threshold and relevance are relative to it.
+	MaxScore(q *Query) float64
+}
+
+// NewScorer returns scorer by name: "subsequence", "count", "tfidf" or
+// "bm25".
+func NewScorer(name string) (Scorer, error) {
+	switch name {
+	case "subsequence":
+		return SubsequenceScorer{}, nil
+	case "count":
+		return TokensCountScorer{}, nil
+	case "tfidf":
+		return TFIDFScorer{}, nil
+	case "bm25":
+		return BM25Scorer{}, nil
+	}
+	return nil, fmt.Errorf("search: unknown scorer %q", name)
+}
+
+// Query is analyzed query given to Scorer.
+type Query struct {
+	Phonetic     []byte
+	Trigram      trigram.Trigram
+	TrigramCount int
+	Vowel        bool
+	// Sequence configures subsequences of matched positions.
+	Sequence seq.Config
+	// IDF reports whether trigrams are weighted by their inverse
+	// document frequency, see Weight.
+	IDF bool
+	// MinScore is the score document must exceed to pass the filter,
+	// zero if IDF is true.
+	MinScore float64
+	// MinMass is the weighted number of matched trigrams document must
+	// exceed to pass the filter if IDF is true.
+	MinMass float64
+	// DocumentCount is the number of indexed documents, or the number of
+	// matched documents if index does not implement lafzi.Stats.
+	DocumentCount int
+	// DocumentFrequency is the number of documents containing token
+	// for every token of Trigram.
+	DocumentFrequency map[string]int
+	// Stats is statistics of index, nil if index does not implement
+	// lafzi.Stats.
+	Stats lafzi.Stats
+}
+
+// idf returns inverse document frequency of token using smoothed
+// log(1 + N/df).
+func (q *Query) idf(token string) float64 {
+	df := q.DocumentFrequency[token]
+	if df == 0 {
+		return 0
+	}
+	return math.Log(1 + float64(q.DocumentCount)/float64(df))
+}
+
+// Weight returns weight of token, that is its inverse document frequency
+// if q.IDF is true, otherwise 1.
+func (q *Query) Weight(token string) float64 {
+	if !q.IDF {
+		return 1
+	}
+	return q.idf(token)
+}
+
+// Mass returns weighted number of query trigrams, it equals TrigramCount
+// if q.IDF is false.
+func (q *Query) Mass() float64 {
+	var mass float64
+	for _, token := range q.Trigram {
+		mass += q.Weight(token.Token()) * float64(token.Frequency())
+	}
+	return mass
+}
+
+// MatchedMass returns weighted number of query trigrams matched by doc,
+// token appearing n times in query is counted at most n times.
+func (q *Query) MatchedMass(doc *Document) float64 {
+	var mass float64
+	for _, token := range q.Trigram {
+		if tf := doc.TermFrequency[token.Token()]; tf > 0 {
+			mass += q.Weight(token.Token()) * float64(min(token.Frequency(), tf))
+		}
+	}
+	return mass
+}
+
+// passes reports whether doc passes the filter, by its weighted number
+// of matched trigrams if IDF is true and by its score otherwise.
+func (q *Query) passes(doc *Document) bool {
+	if q.IDF {
+		return q.MatchedMass(doc) > q.MinMass
+	}
+	return doc.Score > q.MinScore
+}
+
+// subsequence returns subsequences of doc at least minLength long and
+// q.Sequence.MinRunLength long.
+func (q *Query) subsequence(doc *Document, minLength float64) []seq.Subsequence {
+	c := q.Sequence
+	if n := int(math.Ceil(minLength)); n > c.MinRunLength {
+		c.MinRunLength = n
+	}
+	return doc.Sequence.SubsequenceConfig(c)
+}
+
+// SubsequenceScorer scores document by its best subsequence of trigram
+// positions, that is its length times its compactness measured by
+// Query.Sequence.
+type SubsequenceScorer struct{}
+
+// Score ...
+func (SubsequenceScorer) Score(q *Query, doc *Document) float64 {
+	doc.Subsequence = q.subsequence(doc, q.MinScore)
+	if len(doc.Subsequence) == 0 {
+		return 0
+	}
+	return doc.Subsequence[0].Score()
+}
+
+// MaxScore ...
+func (SubsequenceScorer) MaxScore(q *Query) float64 {
+	return float64(q.TrigramCount)
+}
+
+// TokensCountScorer scores document by the number of matched query
+// trigrams, weighted by Query.Weight if Query.IDF is true.
+type TokensCountScorer struct{}
+
+// Score ...
+func (TokensCountScorer) Score(q *Query, doc *Document) float64 {
+	if q.IDF {
+		return q.MatchedMass(doc)
+	}
+	return float64(doc.TokensCount)
+}
+
+// MaxScore ...
+func (TokensCountScorer) MaxScore(q *Query) float64 {
+	return q.Mass()
+}
+
+// TFIDFScorer scores document by sum of sublinear term frequency times
+// inverse document frequency of matched query trigrams. Term frequency
+// is capped at frequency of the trigram in query, so long document
+// repeating a few query trigrams does not outrank exact match.
+type TFIDFScorer struct{}
+
+// Score ...
+func (TFIDFScorer) Score(q *Query, doc *Document) float64 {
+	var score float64
+	for _, token := range q.Trigram {
+		if tf := min(token.Frequency(), doc.TermFrequency[token.Token()]); tf > 0 {
+			score += (1 + math.Log(float64(tf))) * q.idf(token.Token())
+		}
+	}
+	return score
+}
+
+// MaxScore ...
+func (TFIDFScorer) MaxScore(q *Query) float64 {
+	var score float64
+	for _, token := range q.Trigram {
+		score += (1 + math.Log(float64(token.Frequency()))) * q.idf(token.Token())
+	}
+	return score
+}
+
+// BM25 parameters used by zero BM25Scorer.
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// BM25Scorer scores document using Okapi BM25 with trigrams as terms.
+// Zero K1 and B use DefaultBM25K1 and DefaultBM25B. Document length is
+// only normalized if index implements lafzi.Stats. Term frequency is
+// capped at frequency of the trigram in query, as TFIDFScorer does.
+type BM25Scorer struct {
+	K1, B float64
+}
+
+func (s BM25Scorer) params() (k1, b float64) {
+	k1, b = s.K1, s.B
+	if k1 == 0 {
+		k1 = DefaultBM25K1
+	}
+	if b == 0 {
+		b = DefaultBM25B
+	}
+	return k1, b
+}
+
+// idf returns BM25 inverse document frequency of token, it is zero
+// rather than negative if df exceeds the number of documents, such as
+// matched documents in scope counted without lafzi.Stats.
+func (s BM25Scorer) idf(q *Query, token string) float64 {
+	df := float64(q.DocumentFrequency[token])
+	n := float64(q.DocumentCount)
+	if df == 0 || df > n {
+		return 0
+	}
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// saturation returns BM25 term frequency component of tf in document of
+// length normalized by norm.
+func saturation(tf, k1, norm float64) float64 {
+	return tf * (k1 + 1) / (tf + k1*norm)
+}
+
+// Score ...
+func (s BM25Scorer) Score(q *Query, doc *Document) float64 {
+	k1, b := s.params()
+	norm := 1.0
+	if q.Stats != nil {
+		if avg := q.Stats.AverageLength(q.Vowel); avg > 0 {
+			norm = 1 - b + b*float64(q.Stats.DocumentLength(doc.ID, q.Vowel))/avg
+		}
+	}
+
+	var score float64
+	for _, token := range q.Trigram {
+		if tf := min(token.Frequency(), doc.TermFrequency[token.Token()]); tf > 0 {
+			score += s.idf(q, token.Token()) * saturation(float64(tf), k1, norm)
+		}
+	}
+	return score
+}
+
+// MaxScore returns score of the shortest document matching every query
+// trigram as often as query, so no document scores higher.
+func (s BM25Scorer) MaxScore(q *Query) float64 {
+	k1, b := s.params()
+	norm := 1.0
+	if q.Stats != nil && q.Stats.AverageLength(q.Vowel) > 0 {
+		norm = 1 - b
+	}
+	var score float64
+	for _, token := range q.Trigram {
+		score += s.idf(q, token.Token()) * saturation(float64(token.Frequency()), k1, norm)
+	}
+	return score
+}
@@ -0,0 +1,120 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/memory"
+	"github.com/billyzaelani/go-lafzi/search"
+)
+
+func TestScorer(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		scorer   string
+		q        string
+		expected []int
+	}{
+		{"subsequence", "BSMLHRHMN", []int{1, 3, 2}},
+		{"count", "BSMLHRHMN", []int{1, 3, 2}},
+		// equal weight, ties are ordered by id
+		{"tfidf", "RHMNRHM", []int{1, 3}},
+		// shorter document is preferred
+		{"bm25", "RHMNRHM", []int{3, 1}},
+		{"bm25", "LLHRBL", []int{2, 1}},
+	}
+
+	for _, table := range tables {
+		scorer, err := search.NewScorer(table.scorer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := search.Options{Scorer: scorer}
+		res, err := s.Search([]byte(table.q), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("scorer: %s error, expected: %d docs, actual: %d docs", table.scorer, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("scorer: %s error, expected: %d, actual: %d", table.scorer, id, res.Docs[i].ID)
+			}
+		}
+	}
+
+	if _, err := search.NewScorer("xxx"); err == nil {
+		t.Errorf("expected: error, actual: %v", err)
+	}
+}
+
+// unweightedIndex hides lafzi.Stats of its index.
+type unweightedIndex struct {
+	lafzi.Index
+}
+
+func TestBM25Scorer(t *testing.T) {
+	// qul huwallahu ahad (112:1) against long verse repeating some of
+	// its trigrams such as 2:282
+	docs := []memory.Document{
+		{ID: 1, Phonetic: []byte("KLHWLHWLHWLHWLHWLHWLHWLHWLHWLHWLHMN")},
+		{ID: 2, Phonetic: []byte("KLHWLHXHD")},
+		{ID: 3, Phonetic: []byte("KLHWLH")},
+	}
+	a := make(alquran, len(docs))
+	for i := range a {
+		a[i].Info = lafzi.Info{ChapterNo: i + 1, VerseNo: 1}
+	}
+	index := memory.NewIndex(docs, docs)
+	tables := []struct {
+		index    lafzi.Index
+		scope    []search.Scope
+		expected []int
+	}{
+		{index, nil, []int{2, 3, 1}},
+		{unweightedIndex{index}, nil, []int{2, 1, 3}},
+		// df of every token exceeds the number of matched documents
+		{unweightedIndex{index}, []search.Scope{{Division: search.Chapter, From: 2, To: 2}}, []int{2}},
+	}
+
+	for _, table := range tables {
+		s := search.NewService(phoneticEncoder{}, table.index, a)
+		res, err := s.Search([]byte("KLHWLHXHD"), search.Options{Scorer: search.BM25Scorer{}, Scope: table.scope})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []int
+		for _, doc := range res.Docs {
+			actual = append(actual, doc.ID)
+			if doc.Score < 0 || doc.Score > res.MaxScore {
+				t.Errorf("id: %d error, expected: score in [0, %v], actual: %v", doc.ID, res.MaxScore, doc.Score)
+			}
+		}
+		if !reflect.DeepEqual(table.expected, actual) {
+			t.Errorf("scope: %v error, expected: %v, actual: %v", table.scope, table.expected, actual)
+		}
+	}
+}
+
+func TestTFIDFScorerRepeated(t *testing.T) {
+	// qul huwallahu ahad (112:1) against long verse repeating some of
+	// its trigrams such as 2:282
+	docs := []memory.Document{
+		{ID: 1, Phonetic: []byte("KLHWLHWLHWLHWLHWLHWLHWLHWLHWLHWLHMN")},
+		{ID: 2, Phonetic: []byte("KLHWLHXHD")},
+	}
+	s := search.NewService(phoneticEncoder{}, memory.NewIndex(docs, docs), make(alquran, len(docs)))
+	res, err := s.Search([]byte("KLHWLHXHD"), search.Options{Scorer: search.TFIDFScorer{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Docs) != 2 || res.Docs[0].ID != 2 {
+		t.Errorf("expected: 2 first, actual: %+v", res.Docs)
+	}
+	if res.Docs[0].Score != res.MaxScore {
+		t.Errorf("expected: %v, actual: %v", res.MaxScore, res.Docs[0].Score)
+	}
+}
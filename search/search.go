@@ -1,17 +1,108 @@
 package search
 
 import (
+	"container/heap"
+	"context"
+	"fmt"
 	"sort"
+	"sync"
 
 	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/pkg/align"
 	"github.com/billyzaelani/go-lafzi/pkg/phonetic"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/arabic"
 	seq "github.com/billyzaelani/go-lafzi/pkg/sequence"
 	"github.com/billyzaelani/go-lafzi/pkg/trigram"
 )
 
 // Service ...
 type Service interface {
-	Search(query []byte, vowel bool) Result
+	// Search is like SearchContext with background context.
+	Search(query []byte, opts Options) (Result, error)
+	// SearchContext searches query, it returns error when index fails to
+	// read or ctx is done before search finished.
+	SearchContext(ctx context.Context, query []byte, opts Options) (Result, error)
+}
+
+// Options are per search settings.
+type Options struct {
+	// Vowel searches using phonetic code with vowel.
+	Vowel bool
+	// ScoreOrder ranks documents by their best subsequence, otherwise
+	// by the number of matched tokens.
+	ScoreOrder bool
+	// Scorer ranks documents, nil uses SubsequenceScorer if ScoreOrder
+	// is true and TokensCountScorer otherwise.
+	Scorer Scorer
+	// Filter drops documents scoring under FilterThreshold of
+	// maximum score of the scorer.
+	Filter          bool
+	FilterThreshold float64
+	// Sequence configures splitting and scoring subsequences of matched
+	// positions, zero fields use sequence.DefaultConfig of Vowel.
+	Sequence seq.Config
+	// IDF weights every query trigram by its inverse document frequency,
+	// so rare trigrams count more than common ones when counting matched
+	// tokens and filtering documents.
+	IDF bool
+	// Limit is the maximum number of documents returned after skipping
+	// Offset documents, only Offset+Limit best documents are kept while
+	// ranking. Zero Limit means no limit.
+	Limit, Offset int
+	// Rerank orders the best Rerank documents by local alignment of the
+	// query phonetic code to phonetic code of their verse, before
+	// skipping Offset documents. Zero Rerank disables it.
+	Rerank int
+	// Alignment scores the alignment, nil uses align.DefaultScoring.
+	Alignment *align.Scoring
+	// Scope restricts search to verses in any of its scopes, empty Scope
+	// searches the whole Quran. Verses out of scope are skipped while
+	// matching trigrams.
+	Scope []Scope
+	// Verses is the maximum number of adjacent verses of a chapter
+	// searched as continuous text, so query may run across end of verse.
+	// Document spanning verses is only found if its best subsequence
+	// does. Zero or one searches every verse alone.
+	Verses int
+	// Language selects translation of documents, alquran must be a
+	// lafzi.Translator having it. Empty Language keeps Ayat.Translation.
+	Language string
+}
+
+// sequence returns opts.Sequence with zero fields set to default config
+// of phonetic code with or without vowel.
+func (opts Options) sequence(vowel bool) seq.Config {
+	c, def := opts.Sequence, seq.DefaultConfig(vowel)
+	if c.MaxGap <= 0 {
+		c.MaxGap = def.MaxGap
+	}
+	if c.GapPenalty == nil {
+		c.GapPenalty = def.GapPenalty
+	}
+	return c
+}
+
+func (opts Options) scorer() Scorer {
+	switch {
+	case opts.Scorer != nil:
+		return opts.Scorer
+	case opts.ScoreOrder:
+		return SubsequenceScorer{}
+	default:
+		return TokensCountScorer{}
+	}
+}
+
+// DefaultFilterThreshold ...
+const DefaultFilterThreshold = 0.50
+
+// DefaultOptions returns options used by lafzi by default.
+func DefaultOptions() Options {
+	return Options{
+		ScoreOrder:      true,
+		Filter:          true,
+		FilterThreshold: DefaultFilterThreshold,
+	}
 }
 
 type searchService struct {
@@ -19,30 +110,36 @@ type searchService struct {
 
 	index   lafzi.Index
 	alquran lafzi.Alquran
-
-	scoreOrder, filter bool
-	filterThreshold    float64
+	// verse encodes Ayat.Arabic the same way as the index
+	verse phonetic.OffsetEncoder
+	// script encodes query written in arabic script
+	script phonetic.Encoder
 }
 
-var defaultFilterThreshold = 0.50
-
-// NewService ...
+// NewService returns search service of index built from alquran in
+// uthmani script. The service is safe for concurrent use as long as
+// encoder, index and alquran are.
 func NewService(encoder phonetic.Encoder, index lafzi.Index, alquran lafzi.Alquran) Service {
+	return NewServiceScript(encoder, index, alquran, arabic.LettersUthmani)
+}
+
+// NewServiceScript is like NewService but index is built from alquran in
+// script, such as Manifest.Script of package file.
+func NewServiceScript(encoder phonetic.Encoder, index lafzi.Index, alquran lafzi.Alquran, script arabic.LettersMode) Service {
 	return &searchService{
-		Encoder:         encoder,
-		index:           index,
-		alquran:         alquran,
-		scoreOrder:      true,
-		filter:          true,
-		filterThreshold: defaultFilterThreshold,
+		Encoder: encoder,
+		index:   index,
+		alquran: alquran,
+		verse:   arabic.NewEncoder(script),
+		script:  arabic.NewEncoder(arabic.LettersSimple),
 	}
 }
 
-type vowelSetter interface {
-	SetVowel(vowel bool)
+func (s *searchService) Search(q []byte, opts Options) (Result, error) {
+	return s.SearchContext(context.Background(), q, opts)
 }
 
-func (s *searchService) Search(q []byte, v bool) Result {
+func (s *searchService) SearchContext(ctx context.Context, q []byte, opts Options) (Result, error) {
 	// query
 	// -> phonetic encoding
 	// -> trigram tokenization
@@ -50,108 +147,246 @@ func (s *searchService) Search(q []byte, v bool) Result {
 	// -> document rangking
 	// -> search result (documents)
 
+	if err := s.checkLanguage(opts.Language); err != nil {
+		return Result{}, err
+	}
+
 	// [1] phonetic encoding
-	qPhonetic := s.phoneticEncoding(q, v)
+	qPhonetic, vowel := s.phoneticEncoding(q, opts.Vowel)
 
 	// [2] trigram tokenization
 	qTrigram := trigram.Extract(qPhonetic)
 	qTrigramLen := trigram.Count(qPhonetic)
 	if qTrigramLen <= 0 {
-		return Result{Query: string(q), PhoneticCode: string(qPhonetic), Docs: []Document{}}
+		return Result{Query: string(q), PhoneticCode: string(qPhonetic), Vowel: vowel, Docs: []Document{}}, nil
 	}
 
 	// [3] trigram matching
-	matchedDocs := s.trigramMatching(qTrigram, v)
+	matchedDocs, df, err := s.trigramMatching(ctx, qTrigram, vowel, opts.Scope)
+	if err != nil {
+		return Result{}, err
+	}
 
 	// [4] document rangking
-	minScore := s.filterThreshold * float64(qTrigramLen)
-	docs := s.documentRangking(matchedDocs, minScore)
+	query := &Query{
+		Phonetic:          qPhonetic,
+		Trigram:           qTrigram,
+		TrigramCount:      qTrigramLen,
+		Vowel:             vowel,
+		IDF:               opts.IDF,
+		Sequence:          opts.sequence(vowel),
+		DocumentCount:     len(matchedDocs),
+		DocumentFrequency: df,
+	}
+	if stats, ok := s.index.(lafzi.Stats); ok {
+		query.Stats = stats
+		query.DocumentCount = stats.DocumentCount(vowel)
+		for token := range df {
+			df[token] = stats.DocumentFrequency(token, vowel)
+		}
+	}
+	scorer := opts.scorer()
+	maxScore := scorer.MaxScore(query)
+	// weighted documents are filtered by their matched mass alone
+	if !opts.IDF {
+		query.MinScore = opts.FilterThreshold * maxScore
+	}
+	query.MinMass = opts.FilterThreshold * query.Mass()
+	candidates := make([]*Document, 0, len(matchedDocs))
+	for _, doc := range matchedDocs {
+		candidates = append(candidates, doc)
+	}
+	if opts.Verses > 1 {
+		candidates = append(candidates, s.joinVerses(matchedDocs, qTrigram, vowel, opts.Verses)...)
+	}
+	docs, foundDoc, err := s.documentRangking(ctx, candidates, query, scorer, opts)
+	if err != nil {
+		return Result{}, err
+	}
 
 	// [5] search result
 	for i := range docs {
-		id := docs[i].ID
-		docs[i].Ayat = s.alquran.Ayat(id)
+		if docs[i].verses != nil {
+			err = s.joinAyat(&docs[i], opts.Language)
+		} else {
+			docs[i].Ayat, err = s.ayat(docs[i].ID, opts.Language)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		if docs[i].Subsequence == nil {
+			docs[i].Subsequence = query.subsequence(&docs[i], opts.FilterThreshold*float64(qTrigramLen))
+		}
+		highlight(&docs[i], s.verse, vowel)
 	}
 
 	return Result{
 		Query:           string(q),
 		PhoneticCode:    string(qPhonetic),
+		Vowel:           vowel,
 		TrigramCount:    qTrigramLen,
-		FoundDoc:        len(docs),
-		FilterThreshold: s.filterThreshold,
-		MinScore:        minScore,
+		FoundDoc:        foundDoc,
+		FilterThreshold: opts.FilterThreshold,
+		MinScore:        query.MinScore,
+		MaxScore:        maxScore,
 		Docs:            docs,
+	}, nil
+}
+
+// checkLanguage returns error if alquran has no translation in lang.
+func (s *searchService) checkLanguage(lang string) error {
+	if lang == "" {
+		return nil
+	}
+	if tr, ok := s.alquran.(lafzi.Translator); ok {
+		for _, l := range tr.Languages() {
+			if l == lang {
+				return nil
+			}
+		}
 	}
+	return fmt.Errorf("search: no translation in %q", lang)
 }
 
-func (s *searchService) phoneticEncoding(q []byte, v bool) []byte {
-	if vSetter, ok := s.Encoder.(vowelSetter); ok {
-		vSetter.SetVowel(v)
+// ayat returns verse id with translation in lang, empty lang keeps
+// Ayat.Translation.
+func (s *searchService) ayat(id int, lang string) (lafzi.Ayat, error) {
+	ayat := s.alquran.Ayat(id)
+	if lang == "" {
+		return ayat, nil
 	}
-	return s.Encode(q)
+	tr, ok := s.alquran.(lafzi.Translator)
+	if !ok {
+		return ayat, fmt.Errorf("search: no translation in %q", lang)
+	}
+	var err error
+	ayat.Translation, err = tr.Translation(id, lang)
+	return ayat, err
 }
 
-func (s *searchService) trigramMatching(t trigram.Trigram, v bool) map[int]*Document {
+// phoneticEncoding encodes q written in arabic script with the script
+// encoder, otherwise with the service encoder. It returns whether the
+// phonetic code keeps vowel.
+func (s *searchService) phoneticEncoding(q []byte, v bool) ([]byte, bool) {
+	if arabic.IsArabic(q) {
+		// arabic without harakat has no vowel to search with
+		v = v && arabic.HasHarakat(q)
+		return s.script.Encode(q, v), v
+	}
+	return s.Encode(q, v), v
+}
+
+// trigramMatching returns documents in scopes matching any token of t
+// and document frequency of every token.
+func (s *searchService) trigramMatching(ctx context.Context, t trigram.Trigram, v bool, scopes []Scope) (map[int]*Document, map[string]int, error) {
 	matchedDocs := make(map[int]*Document)
+	df := make(map[string]int, len(t))
+	// documents out of scopes, so every document is looked up once
+	var outOfScope map[int]bool
+	if len(scopes) > 0 {
+		outOfScope = make(map[int]bool)
+	}
 	for _, token := range t {
-		docs := s.index.Search(token.Token(), v)
+		docs, err := s.index.Search(ctx, token.Token(), v)
+		if err != nil {
+			return nil, nil, err
+		}
+		df[token.Token()] = len(docs)
 		for _, doc := range docs {
 			term := doc.Term
 			if matchedDoc, ok := matchedDocs[doc.ID]; ok {
 				matchedDoc.TokensCount += min(token.Frequency(), len(term))
-			} else {
+			} else if outOfScope[doc.ID] {
+				continue
+			} else if len(scopes) == 0 || inScope(scopes, s.alquran.Ayat(doc.ID).Info) {
 				matchedDocs[doc.ID] = newDocument(doc.ID)
+			} else {
+				outOfScope[doc.ID] = true
+				continue
 			}
 
 			matchedDocs[doc.ID].addTerm(token, term)
 		}
 	}
-	return matchedDocs
+	return matchedDocs, df, nil
 }
 
-func (s *searchService) documentRangking(matchedDocs map[int]*Document, minScore float64) documents {
-	if s.scoreOrder {
-		for _, doc := range matchedDocs {
-			doc.Subsequence = doc.Sequence.Subsequence(minScore)
-			if len(doc.Subsequence) > 0 {
-				doc.Score = doc.Subsequence[0].Score()
+func (s *searchService) documentRangking(ctx context.Context, matchedDocs []*Document,
+	q *Query, scorer Scorer, opts Options) ([]Document, int, error) {
+	offset := opts.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	// only offset+limit best documents, or documents to rerank, are kept
+	top := topDocuments{}
+	if opts.Limit > 0 {
+		top.k = offset + opts.Limit
+		if opts.Rerank > top.k {
+			top.k = opts.Rerank
+		}
+	}
+	var foundDoc int
+	for _, doc := range matchedDocs {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		doc.Score = scorer.Score(q, doc)
+		// filter document
+		if opts.Filter && !q.passes(doc) {
+			releaseDocument(doc)
+			continue
+		}
+		if doc.verses != nil {
+			if doc.Subsequence == nil {
+				doc.Subsequence = q.subsequence(doc, opts.FilterThreshold*float64(q.TrigramCount))
+			}
+			if !crossesVerses(doc) {
+				releaseDocument(doc)
+				continue
 			}
 		}
-	} else {
-		for _, doc := range matchedDocs {
-			doc.Score = float64(doc.TokensCount)
+		foundDoc++
+		if dropped := top.add(doc); dropped != nil {
+			releaseDocument(dropped)
 		}
 	}
-	// populate document
-	docs := make(documents, 0, len(matchedDocs))
-	for _, doc := range matchedDocs {
-		docs = append(docs, *doc)
+
+	docs := top.sorted()
+	if opts.Rerank > 0 {
+		if err := s.rerank(ctx, docs, q, opts); err != nil {
+			return nil, 0, err
+		}
 	}
-	// sort based on score, lower id have highest priority
-	sort.Sort(docs)
-	// filter document
-	var foundDoc int
-	if s.filter {
-		foundDoc = sort.Search(len(docs), func(i int) bool {
-			return docs[i].Score <= minScore
-		})
-	} else {
-		foundDoc = len(docs)
+	if offset >= len(docs) {
+		return []Document{}, foundDoc, nil
 	}
-
-	return docs[:foundDoc]
-}
-
-func (s *searchService) SetScoreOrder(scoreOrder bool) {
-	s.scoreOrder = scoreOrder
-}
-
-func (s *searchService) SetFilter(filter bool) {
-	s.filter = filter
+	docs = docs[offset:]
+	if opts.Limit > 0 && len(docs) > opts.Limit {
+		docs = docs[:opts.Limit]
+	}
+	return docs, foundDoc, nil
 }
 
-func (s *searchService) FilterThreshold(th float64) {
-	s.filterThreshold = th
+// rerank orders the first opts.Rerank docs by score of local alignment
+// of query phonetic code to phonetic code of their verse, the rest of docs
+// keep their order.
+func (s *searchService) rerank(ctx context.Context, docs []Document, q *Query, opts Options) error {
+	scoring := opts.Alignment
+	if scoring == nil {
+		def := align.DefaultScoring()
+		scoring = &def
+	}
+	n := min(opts.Rerank, len(docs))
+	for i := range docs[:n] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		docs[i].Alignment = align.Local(q.Phonetic, s.phoneticCode(&docs[i], q.Vowel), *scoring)
+	}
+	sort.SliceStable(docs[:n], func(i, j int) bool {
+		return docs[i].Alignment.Score > docs[j].Alignment.Score
+	})
+	return nil
 }
 
 func min(a, b int) int {
@@ -163,11 +398,20 @@ func min(a, b int) int {
 
 // Result ...
 type Result struct {
-	Query                     string
-	PhoneticCode              string
-	TrigramCount, FoundDoc    int
-	FilterThreshold, MinScore float64
-	Docs                      []Document
+	Query        string
+	PhoneticCode string
+	// Vowel reports whether PhoneticCode keeps vowel, it is false for
+	// arabic query without harakat even if Options.Vowel is true.
+	Vowel                  bool
+	TrigramCount, FoundDoc int
+	FilterThreshold        float64
+	// MinScore is the score document must exceed to pass the filter,
+	// zero if documents are filtered by weighted matched trigrams of
+	// Options.IDF.
+	MinScore float64
+	// MaxScore is score of document matching every query trigram.
+	MaxScore float64
+	Docs     []Document
 }
 
 // Document ...
@@ -176,19 +420,55 @@ type Document struct {
 	lafzi.Ayat
 	Score       float64
 	TokensCount int
+	// TermFrequency is the number of positions of every matched token.
+	TermFrequency map[string]int
 	seq.Sequence
-	Subsequence       []seq.Subsequence
+	Subsequence []seq.Subsequence
+	// Alignment is local alignment of query phonetic code to phonetic
+	// code of the verse, it is only set for reranked documents.
+	Alignment align.Result
+	// To is the last verse if the document spans adjacent verses, Ayat
+	// then has text of every verse from the first. It is zero for
+	// document of a single verse.
+	To lafzi.Info
+	// verses are offsets of every verse of document spanning verses.
+	verses []verseOffset
+	// HighlightPosition are positions in phonetic code of the verse
+	// covered by the best subsequence, starting from 1.
 	HighlightPosition []int
+	// Highlight are spans of Ayat.Arabic words matching the query.
+	Highlight []Span
+}
+
+// documentPool reuses matched documents dropped while ranking, together
+// with their positions and term frequencies.
+var documentPool = sync.Pool{
+	New: func() interface{} {
+		return &Document{TermFrequency: make(map[string]int)}
+	},
 }
 
 func newDocument(id int) *Document {
-	return &Document{
-		ID:          id,
-		TokensCount: 1,
+	d := documentPool.Get().(*Document)
+	d.ID = id
+	d.TokensCount = 1
+	return d
+}
+
+// releaseDocument resets d and puts it back to documentPool, d must not
+// be used afterwards.
+func releaseDocument(d *Document) {
+	s, tf := d.Sequence, d.TermFrequency
+	s.Reset()
+	for token := range tf {
+		delete(tf, token)
 	}
+	*d = Document{Sequence: s, TermFrequency: tf}
+	documentPool.Put(d)
 }
 
 func (d *Document) addTerm(token trigram.Token, term lafzi.Term) {
+	d.TermFrequency[token.Token()] = len(term)
 	pos := token.Position()
 	order := seq.X
 	if len(pos) == 1 {
@@ -197,23 +477,76 @@ func (d *Document) addTerm(token trigram.Token, term lafzi.Term) {
 	d.Insert(order, term...)
 }
 
-type documents []Document
+// better reports whether a ranks before b, on same score lower id and
+// then fewer verses have higher priority.
+func better(a, b *Document) bool {
+	switch {
+	case a.Score != b.Score:
+		return a.Score > b.Score
+	case a.ID != b.ID:
+		return a.ID < b.ID
+	default:
+		return len(a.verses) < len(b.verses)
+	}
+}
+
+// topDocuments keeps k best documents in a heap with the worst on top,
+// it keeps all documents if k is zero.
+type topDocuments struct {
+	k    int
+	docs []*Document
+}
 
-func (docs documents) Len() int {
-	return len(docs)
+// add adds doc and returns document dropped to keep k best documents, it
+// returns nil if no document is dropped.
+func (t *topDocuments) add(doc *Document) *Document {
+	switch {
+	case t.k == 0:
+		t.docs = append(t.docs, doc)
+	case len(t.docs) < t.k:
+		heap.Push(t, doc)
+	case better(doc, t.docs[0]):
+		dropped := t.docs[0]
+		t.docs[0] = doc
+		heap.Fix(t, 0)
+		return dropped
+	default:
+		return doc
+	}
+	return nil
 }
 
-func (docs documents) Less(i, j int) bool {
-	var b bool
-	if docs[i].Score == docs[j].Score {
-		b = docs[i].ID < docs[j].ID
-	} else {
-		b = docs[i].Score > docs[j].Score
+// sorted returns copy of kept documents from the best.
+func (t *topDocuments) sorted() []Document {
+	sort.Slice(t.docs, func(i, j int) bool {
+		return better(t.docs[i], t.docs[j])
+	})
+	docs := make([]Document, len(t.docs))
+	for i, doc := range t.docs {
+		docs[i] = *doc
 	}
+	return docs
+}
 
-	return b
+func (t *topDocuments) Len() int {
+	return len(t.docs)
+}
+
+func (t *topDocuments) Less(i, j int) bool {
+	return better(t.docs[j], t.docs[i])
+}
+
+func (t *topDocuments) Swap(i, j int) {
+	t.docs[i], t.docs[j] = t.docs[j], t.docs[i]
+}
+
+func (t *topDocuments) Push(x interface{}) {
+	t.docs = append(t.docs, x.(*Document))
 }
 
-func (docs documents) Swap(i, j int) {
-	docs[i], docs[j] = docs[j], docs[i]
+func (t *topDocuments) Pop() interface{} {
+	n := len(t.docs)
+	doc := t.docs[n-1]
+	t.docs = t.docs[:n-1]
+	return doc
 }
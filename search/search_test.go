@@ -0,0 +1,583 @@
+package search_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/memory"
+	"github.com/billyzaelani/go-lafzi/pkg/align"
+	"github.com/billyzaelani/go-lafzi/pkg/phonetic/indonesia"
+	"github.com/billyzaelani/go-lafzi/pkg/sequence"
+	"github.com/billyzaelani/go-lafzi/search"
+)
+
+// phoneticEncoder treats query as phonetic code.
+type phoneticEncoder struct{}
+
+func (phoneticEncoder) Encode(src []byte, vowel bool) []byte {
+	return src
+}
+
+type alquran []lafzi.Ayat
+
+func (a alquran) Ayat(id int) lafzi.Ayat {
+	return a[id-1]
+}
+
+var (
+	// Al-Fatihah(1) verse: 1-3
+	testIndex = memory.NewIndex(
+		[]memory.Document{
+			{ID: 1, Phonetic: []byte("BISMILAHIRAHMANIRAHIM")},
+			{ID: 2, Phonetic: []byte("XALHAMDULILAHIRABILXALAMIN")},
+			{ID: 3, Phonetic: []byte("XARAHMANIRAHIM")},
+		},
+		[]memory.Document{
+			{ID: 1, Phonetic: []byte("BSMLHRHMNRHM")},
+			{ID: 2, Phonetic: []byte("XLHMDLLHRBLXLMN")},
+			{ID: 3, Phonetic: []byte("XRHMNRHM")},
+		},
+	)
+	testAlquran = alquran{
+		{
+			Info:   lafzi.Info{ChapterNo: 1, ChapterName: "Al-Fatihah", VerseNo: 1},
+			Arabic: "بِسْمِ ٱللَّهِ ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ",
+		},
+		{
+			Info:   lafzi.Info{ChapterNo: 1, ChapterName: "Al-Fatihah", VerseNo: 2},
+			Arabic: "ٱلْحَمْدُ لِلَّهِ رَبِّ ٱلْعَـٰلَمِينَ",
+		},
+		{
+			Info:   lafzi.Info{ChapterNo: 1, ChapterName: "Al-Fatihah", VerseNo: 3},
+			Arabic: "ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ",
+		},
+	}
+)
+
+func TestSearch(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		q        string
+		vowel    bool
+		expected []int
+	}{
+		{"RHMNRHM", false, []int{1, 3}},
+		{"XLHMDLLH", false, []int{2}},
+		{"XARAHMANI", true, []int{3, 1}},
+		{"XX", true, []int{}},
+	}
+
+	for _, table := range tables {
+		opts := search.DefaultOptions()
+		opts.Vowel = table.vowel
+		res, err := s.Search([]byte(table.q), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("query: %s error, expected: %d docs, actual: %d docs", table.q, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("query: %s error, expected: %d, actual: %d", table.q, id, res.Docs[i].ID)
+			}
+		}
+	}
+}
+
+func TestSearchContextCanceled(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.SearchContext(ctx, []byte("RHMNRHM"), search.DefaultOptions())
+	if err != context.Canceled {
+		t.Errorf("expected: %v, actual: %v", context.Canceled, err)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		opts     search.Options
+		foundDoc int
+		expected []int
+	}{
+		{search.Options{ScoreOrder: true}, 3, []int{1, 3, 2}},
+		{search.Options{ScoreOrder: true, Filter: true, FilterThreshold: 0.25}, 2, []int{1, 3}},
+		{search.Options{ScoreOrder: true, Filter: true, FilterThreshold: 0.9}, 1, []int{1}},
+		{search.Options{ScoreOrder: true, Limit: 1, Offset: 1}, 3, []int{3}},
+		{search.Options{ScoreOrder: true, Offset: 5}, 3, []int{}},
+		// query has 7 trigrams
+		{search.Options{ScoreOrder: true, Filter: true, Sequence: sequence.Config{MinRunLength: 7}}, 1, []int{1}},
+		{search.Options{ScoreOrder: true, Filter: true, Sequence: sequence.Config{MinRunLength: 8}}, 0, []int{}},
+	}
+
+	for _, table := range tables {
+		res, err := s.Search([]byte("BSMLHRHMN"), table.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.FoundDoc != table.foundDoc {
+			t.Errorf("options: %+v error, expected found: %d, actual found: %d", table.opts, table.foundDoc, res.FoundDoc)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("options: %+v error, expected: %d docs, actual: %d docs", table.opts, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("options: %+v error, expected: %d, actual: %d", table.opts, id, res.Docs[i].ID)
+			}
+		}
+	}
+}
+
+// TestSearchConcurrent runs queries with and without vowel in parallel,
+// run with -race to detect shared state.
+func TestSearchConcurrent(t *testing.T) {
+	s := search.NewService(indonesia.Encoder{}, testIndex, testAlquran)
+	queries := []string{"bismillahir rahmanir rahim", "arrahmanirrahim", "alhamdu lillahi"}
+	expected := make(map[string]search.Result)
+	for _, q := range queries {
+		for _, v := range []bool{true, false} {
+			opts := search.DefaultOptions()
+			opts.Vowel = v
+			res, err := s.Search([]byte(q), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected[fmt.Sprint(q, v)] = res
+		}
+	}
+
+	for i := 0; i < 8; i++ {
+		q, v := queries[i%len(queries)], i%2 == 0
+		t.Run(fmt.Sprint(q, v), func(t *testing.T) {
+			t.Parallel()
+			opts := search.DefaultOptions()
+			opts.Vowel = v
+			for j := 0; j < 50; j++ {
+				res, err := s.Search([]byte(q), opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exp := expected[fmt.Sprint(q, v)]; !reflect.DeepEqual(exp, res) {
+					t.Fatalf("query: %s error, expected: %+v, actual: %+v", q, exp, res)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		q        string
+		vowel    bool
+		expected []string
+	}{
+		{"RHMNRHM", false, []string{"ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ", "ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ"}},
+		{"BISMILAH", true, []string{"بِسْمِ ٱللَّهِ"}},
+		{"LHMDLLH", false, []string{"ٱلْحَمْدُ لِلَّهِ"}},
+		{"LLHRBL", false, []string{"لِلَّهِ رَبِّ ٱلْعَـٰلَمِينَ"}},
+	}
+
+	for _, table := range tables {
+		opts := search.DefaultOptions()
+		opts.Vowel = table.vowel
+		res, err := s.Search([]byte(table.q), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("query: %s error, expected: %d docs, actual: %d docs", table.q, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, expected := range table.expected {
+			doc := res.Docs[i]
+			if len(doc.Highlight) != 1 {
+				t.Errorf("query: %s error, expected: 1 span, actual: %v", table.q, doc.Highlight)
+				continue
+			}
+			actual := doc.Arabic[doc.Highlight[0].Start:doc.Highlight[0].End]
+			if actual != expected {
+				t.Errorf("query: %s error, expected: %s, actual: %s", table.q, expected, actual)
+			}
+		}
+	}
+}
+
+func TestSearchArabic(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		q        string
+		vowel    bool
+		code     string
+		expected []int
+	}{
+		{"ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ", true, "XARAHMANIRAHIM", []int{3, 1}},
+		{"ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ", false, "XRHMNRHM", []int{3, 1}},
+		// without harakat search is done without vowel
+		{"الحمد لله", true, "XLHMDLLH", []int{2}},
+		{"ب", true, "B", []int{}},
+	}
+
+	for _, table := range tables {
+		opts := search.DefaultOptions()
+		opts.Vowel = table.vowel
+		res, err := s.Search([]byte(table.q), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.PhoneticCode != table.code {
+			t.Errorf("query: %s error, expected: %s, actual: %s", table.q, table.code, res.PhoneticCode)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("query: %s error, expected: %d docs, actual: %d docs", table.q, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("query: %s error, expected: %d, actual: %d", table.q, id, res.Docs[i].ID)
+			}
+		}
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	// documents repeating "BSMLH" i%7+1 times, so scores differ and tie
+	docs := make([]memory.Document, 50)
+	for i := range docs {
+		var b bytes.Buffer
+		for j := 0; j <= i%7; j++ {
+			b.WriteString("BSMLHKTB")
+		}
+		docs[i] = memory.Document{ID: i + 1, Phonetic: b.Bytes()}
+	}
+	s := search.NewService(phoneticEncoder{}, memory.NewIndex(docs, docs), make(alquran, len(docs)))
+	q := []byte("BSMLHKTBBSMLH")
+
+	for _, scoreOrder := range []bool{true, false} {
+		opts := search.Options{ScoreOrder: scoreOrder}
+		all, err := s.Search(q, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for offset := 0; offset < 55; offset += 9 {
+			opts.Offset, opts.Limit = offset, 9
+			res, err := s.Search(q, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.FoundDoc != all.FoundDoc {
+				t.Errorf("offset: %d error, expected found: %d, actual found: %d", offset, all.FoundDoc, res.FoundDoc)
+			}
+			end := offset + 9
+			if end > len(all.Docs) {
+				end = len(all.Docs)
+			}
+			if offset > end {
+				offset = end
+			}
+			expected := all.Docs[offset:end]
+			if len(res.Docs) != len(expected) {
+				t.Errorf("offset: %d error, expected: %d docs, actual: %d docs", offset, len(expected), len(res.Docs))
+				continue
+			}
+			for i := range expected {
+				if res.Docs[i].ID != expected[i].ID {
+					t.Errorf("offset: %d error, expected: %d, actual: %d", offset, expected[i].ID, res.Docs[i].ID)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchIDF(t *testing.T) {
+	// common trigrams XLL and LLH against rare LHK and HKT
+	docs := []memory.Document{
+		{ID: 1, Phonetic: []byte("XLLHMN")},
+		{ID: 2, Phonetic: []byte("XLLHRB")},
+		{ID: 3, Phonetic: []byte("XLLHSD")},
+		{ID: 4, Phonetic: []byte("XLLHWT")},
+		{ID: 5, Phonetic: []byte("XLLHFQ")},
+		{ID: 6, Phonetic: []byte("BLHKTB")},
+	}
+	s := search.NewService(phoneticEncoder{}, memory.NewIndex(docs, docs), make(alquran, len(docs)))
+	tables := []struct {
+		opts     search.Options
+		expected []int
+	}{
+		{search.Options{Filter: true, FilterThreshold: 0.4}, []int{1, 2, 3, 4, 5, 6}},
+		{search.Options{Filter: true, FilterThreshold: 0.4, IDF: true}, []int{6}},
+		{search.Options{Filter: true, FilterThreshold: 0.4, IDF: true, ScoreOrder: true}, []int{6}},
+		{search.Options{Filter: true, FilterThreshold: 0.5, IDF: true}, []int{6}},
+		{search.Options{Filter: true, FilterThreshold: 0.5, IDF: true, ScoreOrder: true}, []int{6}},
+		{search.Options{Filter: true, FilterThreshold: 0.8, IDF: true, ScoreOrder: true}, []int{}},
+		{search.Options{Filter: true, FilterThreshold: 0.8, IDF: true}, []int{}},
+	}
+
+	for _, table := range tables {
+		res, err := s.Search([]byte("XLLHKT"), table.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("options: %+v error, expected: %d docs, actual: %d docs", table.opts, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("options: %+v error, expected: %d, actual: %d", table.opts, id, res.Docs[i].ID)
+			}
+		}
+	}
+}
+
+func TestSearchRerank(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	// trigram LLH of verse 2 outweighs the longer RHMN run of verse 3
+	q := []byte("BSMLLHRHMN")
+	tables := []struct {
+		opts     search.Options
+		expected []int
+	}{
+		{search.Options{ScoreOrder: true}, []int{1, 2, 3}},
+		{search.Options{ScoreOrder: true, Rerank: 3}, []int{1, 3, 2}},
+		{search.Options{ScoreOrder: true, Rerank: 3, Limit: 1, Offset: 1}, []int{3}},
+		{search.Options{ScoreOrder: true, Rerank: 1}, []int{1, 2, 3}},
+	}
+
+	for _, table := range tables {
+		res, err := s.Search(q, table.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("options: %+v error, expected: %d docs, actual: %d docs", table.opts, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("options: %+v error, expected: %d, actual: %d", table.opts, id, res.Docs[i].ID)
+			}
+		}
+	}
+
+	res, err := s.Search(q, search.Options{Rerank: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := align.Result{Score: 8, Query: align.Span{Start: 0, End: 10}, Text: align.Span{Start: 0, End: 9}}
+	if actual := res.Docs[0].Alignment; actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestSearchVerses(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		q         string
+		vowel     bool
+		verses    int
+		expected  []string
+		highlight string
+	}{
+		{"RHMNRHMXLHMDLLH", false, 1, []string{"1:2", "1:1", "1:3"}, "ٱلْحَمْدُ لِلَّهِ"},
+		{"RHMNRHMXLHMDLLH", false, 2, []string{"1:1–1:2", "1:2", "1:1", "1:3"},
+			"ٱلرَّحْمَـٰنِ ٱلرَّحِيمِ ٱلْحَمْدُ لِلَّهِ"},
+		{"RAHIMIXALHAMDU", true, 3, []string{"1:1–1:2", "1:2", "1:1", "1:3"}, "ٱلرَّحِيمِ ٱلْحَمْدُ"},
+		// best subsequence within a verse
+		{"XLHMDLLH", false, 3, []string{"1:2"}, "ٱلْحَمْدُ لِلَّهِ"},
+	}
+
+	for _, table := range tables {
+		opts := search.Options{ScoreOrder: true, Vowel: table.vowel, Verses: table.verses}
+		res, err := s.Search([]byte(table.q), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, doc := range res.Docs {
+			actual = append(actual, doc.Reference())
+		}
+		if !reflect.DeepEqual(table.expected, actual) {
+			t.Errorf("query: %s error, expected: %v, actual: %v", table.q, table.expected, actual)
+			continue
+		}
+		doc := res.Docs[0]
+		if len(doc.Highlight) != 1 {
+			t.Errorf("query: %s error, expected: 1 span, actual: %v", table.q, doc.Highlight)
+			continue
+		}
+		if hl := doc.Arabic[doc.Highlight[0].Start:doc.Highlight[0].End]; hl != table.highlight {
+			t.Errorf("query: %s error, expected: %s, actual: %s", table.q, table.highlight, hl)
+		}
+	}
+}
+
+func TestSearchVersesSingle(t *testing.T) {
+	// neighbours of verse 2 match some of its trigrams near their
+	// boundary, joining them to it must not outrank verse 2 alone
+	docs := []memory.Document{
+		{ID: 1, Phonetic: []byte("XYZXYZFGH")},
+		{ID: 2, Phonetic: []byte("BCDEFGH")},
+		{ID: 3, Phonetic: []byte("BCDXYZXYZ")},
+	}
+	a := make(alquran, len(docs))
+	for i := range a {
+		a[i].Info = lafzi.Info{ChapterNo: 3, VerseNo: i + 1}
+	}
+	s := search.NewService(phoneticEncoder{}, memory.NewIndex(docs, docs), a)
+	for _, verses := range []int{2, 3} {
+		for _, filter := range []bool{true, false} {
+			opts := search.Options{ScoreOrder: true, Filter: filter, FilterThreshold: 0.5, Verses: verses}
+			res, err := s.Search([]byte("BCDEFGH"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for _, doc := range res.Docs {
+				actual = append(actual, doc.Reference())
+			}
+			if len(actual) == 0 || actual[0] != "3:2" {
+				t.Errorf("verses: %d, filter: %v error, expected: 3:2 first, actual: %v", verses, filter, actual)
+			}
+		}
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	s := search.NewService(phoneticEncoder{}, testIndex, testAlquran)
+	tables := []struct {
+		scopes   []string
+		expected []int
+	}{
+		{nil, []int{1, 3}},
+		{[]string{"1"}, []int{1, 3}},
+		{[]string{"1:2-3"}, []int{3}},
+		{[]string{"1:1", "1:3"}, []int{1, 3}},
+		{[]string{"2"}, []int{}},
+	}
+
+	for _, table := range tables {
+		opts := search.DefaultOptions()
+		for _, str := range table.scopes {
+			scope, err := search.ParseScope(search.Chapter, str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts.Scope = append(opts.Scope, scope)
+		}
+		res, err := s.Search([]byte("RHMNRHM"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Docs) != len(table.expected) {
+			t.Errorf("scopes: %v error, expected: %d docs, actual: %d docs", table.scopes, len(table.expected), len(res.Docs))
+			continue
+		}
+		for i, id := range table.expected {
+			if res.Docs[i].ID != id {
+				t.Errorf("scopes: %v error, expected: %d, actual: %d", table.scopes, id, res.Docs[i].ID)
+			}
+		}
+	}
+}
+
+// translatedAlquran has translation of verse id in lang written as
+// "lang id".
+type translatedAlquran struct {
+	alquran
+}
+
+func (translatedAlquran) Languages() []string {
+	return []string{"id", "en"}
+}
+
+func (a translatedAlquran) Translation(id int, lang string) (string, error) {
+	if lang != "id" && lang != "en" {
+		return "", fmt.Errorf("no translation in %q", lang)
+	}
+	return fmt.Sprintf("%s %d", lang, id), nil
+}
+
+func TestSearchLanguage(t *testing.T) {
+	tables := []struct {
+		alquran  lafzi.Alquran
+		lang     string
+		verses   int
+		expected []string
+		err      bool
+	}{
+		{translatedAlquran{testAlquran}, "", 1, []string{"", ""}, false},
+		{translatedAlquran{testAlquran}, "en", 1, []string{"en 1", "en 3"}, false},
+		{translatedAlquran{testAlquran}, "en", 2, []string{"en 1 en 2"}, false},
+		{translatedAlquran{testAlquran}, "ms", 1, nil, true},
+		{testAlquran, "en", 1, nil, true},
+	}
+
+	for _, table := range tables {
+		s := search.NewService(phoneticEncoder{}, testIndex, table.alquran)
+		opts := search.DefaultOptions()
+		opts.Language, opts.Verses = table.lang, table.verses
+		q := "RHMNRHM"
+		if table.verses > 1 {
+			q = "RHMNRHMXLHMDLLH"
+		}
+		res, err := s.Search([]byte(q), opts)
+		if table.err {
+			if err == nil {
+				t.Errorf("lang: %s error, expected: error, actual: %v", table.lang, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, doc := range res.Docs {
+			actual = append(actual, doc.Translation)
+		}
+		if !reflect.DeepEqual(table.expected, actual) {
+			t.Errorf("lang: %s error, expected: %q, actual: %q", table.lang, table.expected, actual)
+		}
+	}
+}
+
+// benchService returns service of n documents made of common words, so
+// broad query matches most of them.
+func benchService(n int) search.Service {
+	words := []string{"BSM", "LLH", "RHMN", "RHM", "XLHMD", "RB", "XLMN", "MLK", "YWM", "DN"}
+	docs := make([]memory.Document, n)
+	for i := range docs {
+		var b bytes.Buffer
+		for j := 0; j < 12; j++ {
+			b.WriteString(words[(i*7+j*j)%len(words)])
+		}
+		docs[i] = memory.Document{ID: i + 1, Phonetic: b.Bytes()}
+	}
+	return search.NewService(phoneticEncoder{}, memory.NewIndex(docs, docs), make(alquran, n))
+}
+
+func BenchmarkSearch(b *testing.B) {
+	s := benchService(2000)
+	q := []byte("BSMLLHXLHMDDN")
+	for _, limit := range []int{20, 0} {
+		b.Run(fmt.Sprint("limit", limit), func(b *testing.B) {
+			opts := search.DefaultOptions()
+			opts.Limit = limit
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Search(q, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
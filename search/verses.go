@@ -0,0 +1,140 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	lafzi "github.com/billyzaelani/go-lafzi"
+	"github.com/billyzaelani/go-lafzi/pkg/trigram"
+)
+
+// verseOffset is where a verse starts in document spanning verses, in
+// phonetic code runes and in bytes of Ayat.Arabic.
+type verseOffset struct {
+	phonetic, arabic int
+}
+
+// verseSeparator joins text of verses of document spanning verses.
+const verseSeparator = " "
+
+// joinVerses returns documents spanning 2 to n adjacent verses of the
+// same chapter where every verse is matched. Positions of every verse are
+// shifted by phonetic code length of verses before it, trigrams across
+// verse end are not indexed so they never match. Document is only
+// returned if it matches more query trigrams than any of its verses, so
+// trigrams of neighbouring verses matched by chance do not make it
+// outrank the verse matching the query alone.
+func (s *searchService) joinVerses(matchedDocs map[int]*Document, t trigram.Trigram, v bool, n int) []*Document {
+	var joined []*Document
+	for id := range matchedDocs {
+		chapter := s.alquran.Ayat(id).ChapterNo
+		offsets := []verseOffset{{}}
+		length := s.phoneticLength(id, v)
+		best := matchedDocs[id].TokensCount
+		for next := id + 1; next < id+n; next++ {
+			if _, ok := matchedDocs[next]; !ok || s.alquran.Ayat(next).ChapterNo != chapter {
+				break
+			}
+			offsets = append(offsets, verseOffset{phonetic: length})
+			length += s.phoneticLength(next, v)
+			if tc := matchedDocs[next].TokensCount; tc > best {
+				best = tc
+			}
+			doc := joinDocuments(matchedDocs, id, offsets, t)
+			if doc.TokensCount <= best {
+				releaseDocument(doc)
+				continue
+			}
+			joined = append(joined, doc)
+		}
+	}
+	return joined
+}
+
+// joinDocuments returns document of verses from id at offsets.
+func joinDocuments(matchedDocs map[int]*Document, id int, offsets []verseOffset, t trigram.Trigram) *Document {
+	doc := newDocument(id)
+	doc.verses = append([]verseOffset(nil), offsets...)
+	for i, offset := range offsets {
+		d := matchedDocs[id+i]
+		doc.InsertSequence(&d.Sequence, offset.phonetic)
+		for token, tf := range d.TermFrequency {
+			doc.TermFrequency[token] += tf
+		}
+	}
+	doc.TokensCount = 0
+	for _, token := range t {
+		doc.TokensCount += min(token.Frequency(), doc.TermFrequency[token.Token()])
+	}
+	return doc
+}
+
+// phoneticCode returns phonetic code of verses of doc encoded by verse
+// encoder.
+func (s *searchService) phoneticCode(doc *Document, v bool) []byte {
+	n := len(doc.verses)
+	if n == 0 {
+		n = 1
+	}
+	var code []byte
+	for i := 0; i < n; i++ {
+		code = append(code, s.verse.Encode([]byte(s.alquran.Ayat(doc.ID+i).Arabic), v)...)
+	}
+	return code
+}
+
+// phoneticLength returns the number of runes of phonetic code of verse id.
+func (s *searchService) phoneticLength(id int, v bool) int {
+	if stats, ok := s.index.(lafzi.Stats); ok {
+		// every rune but the last 2 starts a trigram
+		return stats.DocumentLength(id, v) + 2
+	}
+	return utf8.RuneCount(s.verse.Encode([]byte(s.alquran.Ayat(id).Arabic), v))
+}
+
+// crossesVerses reports whether the best subsequence of doc spanning
+// verses starts in its first verse and ends in its last verse, otherwise
+// document of fewer verses has the same subsequence.
+func crossesVerses(doc *Document) bool {
+	if len(doc.Subsequence) == 0 {
+		return false
+	}
+	pos := doc.Subsequence[0].Positions()
+	last := doc.verses[len(doc.verses)-1].phonetic
+	return pos[0] <= doc.verses[1].phonetic && pos[len(pos)-1] > last
+}
+
+// joinAyat sets Ayat of doc spanning verses to text of every verse joined
+// by verseSeparator with translation in lang, and To to the last verse.
+func (s *searchService) joinAyat(doc *Document, lang string) error {
+	var arabic, translation []string
+	var n int
+	for i := range doc.verses {
+		ayat, err := s.ayat(doc.ID+i, lang)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			doc.Ayat.Info = ayat.Info
+		}
+		doc.To = ayat.Info
+		doc.verses[i].arabic = n
+		n += len(ayat.Arabic) + len(verseSeparator)
+		arabic = append(arabic, ayat.Arabic)
+		translation = append(translation, ayat.Translation)
+	}
+	doc.Arabic = strings.Join(arabic, verseSeparator)
+	doc.Translation = strings.Join(translation, verseSeparator)
+	return nil
+}
+
+// Reference returns chapter and verse number of d such as 2:255, or its
+// first and last verse such as 2:255–2:256 if d spans verses.
+func (d Document) Reference() string {
+	ref := fmt.Sprintf("%d:%d", d.ChapterNo, d.VerseNo)
+	if d.To.VerseNo != 0 {
+		ref += fmt.Sprintf("–%d:%d", d.To.ChapterNo, d.To.VerseNo)
+	}
+	return ref
+}
@@ -4,22 +4,65 @@ import (
 	"bytes"
 	"html/template"
 	"io"
+	"io/fs"
 	"log"
 	"math"
 	"net/http"
+	"strings"
+
+	"github.com/billyzaelani/go-lafzi/search"
+	"github.com/billyzaelani/go-lafzi/web"
 )
 
 // Template ...
 var (
-	Layout = template.Must(template.New("base.html").ParseFiles("web/template/layout/base.html", "web/template/layout/footer.html"))
+	Layout *template.Template
 
-	Index = template.Must(template.Must(Layout.Clone()).ParseFiles("web/template/index.html"))
+	Index *template.Template
 
-	About = template.Must(template.Must(Layout.Clone()).ParseFiles("web/template/about.html"))
+	About *template.Template
 
-	Search = template.Must(template.Must(Layout.Clone()).Funcs(fmap).ParseFiles("web/template/search.html"))
+	Search *template.Template
 )
 
+func init() {
+	if err := Load(web.FS("")); err != nil {
+		panic(err)
+	}
+}
+
+// Load parses templates from template directory of fsys such as web.FS,
+// templates are embedded ones until Load is called. It is not safe to
+// call while serving.
+func Load(fsys fs.FS) error {
+	layout, err := template.New("base.html").ParseFS(fsys, "template/layout/base.html", "template/layout/footer.html")
+	if err != nil {
+		return err
+	}
+	parse := func(name string, funcs template.FuncMap) (*template.Template, error) {
+		t, err := layout.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return t.Funcs(funcs).ParseFS(fsys, name)
+	}
+	index, err := parse("template/index.html", nil)
+	if err != nil {
+		return err
+	}
+	about, err := parse("template/about.html", nil)
+	if err != nil {
+		return err
+	}
+	search, err := parse("template/search.html", fmap)
+	if err != nil {
+		return err
+	}
+
+	Layout, Index, About, Search = layout, index, about, search
+	return nil
+}
+
 // ServeHTMLTemplate ...
 func ServeHTMLTemplate(w http.ResponseWriter, r *http.Request, tpl *template.Template, data interface{}) {
 	buf := bytes.Buffer{}
@@ -41,13 +84,34 @@ var fmap = template.FuncMap{
 	"inc": func(i int) int {
 		return i + 1
 	},
-	"relevance": func(score float64, maxScore int) float64 {
-		fmaxScore := float64(maxScore)
-		relevance := math.Min(math.Floor(score/fmaxScore*100), 100)
+	"add": func(a, b int) int {
+		return a + b
+	},
+	"relevance": func(score float64, maxScore float64) float64 {
+		// no query trigram carries weight, such as when every one of
+		// them is in every document
+		if maxScore <= 0 {
+			return 100
+		}
+		relevance := math.Min(math.Floor(score/maxScore*100), 100)
 		if relevance == 0 {
 			relevance = 1
 		}
 
 		return relevance
 	},
+	"highlight": func(text string, spans []search.Span) template.HTML {
+		var b strings.Builder
+		var last int
+		for _, span := range spans {
+			b.WriteString(template.HTMLEscapeString(text[last:span.Start]))
+			b.WriteString(`<span class="hl_block">`)
+			b.WriteString(template.HTMLEscapeString(text[span.Start:span.End]))
+			b.WriteString(`</span>`)
+			last = span.End
+		}
+		b.WriteString(template.HTMLEscapeString(text[last:]))
+
+		return template.HTML(b.String())
+	},
 }
@@ -0,0 +1,22 @@
+// Package web embeds templates and assets of the web interface.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/billyzaelani/go-lafzi/pkg/overlay"
+)
+
+//go:embed asset template/*.html template/layout
+var embedded embed.FS
+
+// FS returns file system of asset and template directories embedded in the
+// binary, overridden by files of directory dir if dir is not empty.
+func FS(dir string) fs.FS {
+	if dir == "" {
+		return embedded
+	}
+	return overlay.New(os.DirFS(dir), embedded)
+}